@@ -24,10 +24,13 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/formatting"
 	"github.com/AlexanderThaller/lablog/src/helper"
+	"github.com/AlexanderThaller/lablog/src/vcs"
 	"github.com/juju/errgo"
 
 	"github.com/spf13/cobra"
@@ -36,6 +39,8 @@ import (
 var flagAddTimeStamp time.Time
 var flagAddTimeStampRaw string
 var flagAddAutoCommit bool
+var flagAddNoCommit bool
+var flagAddNoteEditor bool
 
 func init() {
 	flagAddTimeStamp = time.Now()
@@ -44,8 +49,12 @@ func init() {
 		flagAddTimeStamp.String(), "The timestamp for which to record the note.")
 	cmdAdd.PersistentFlags().BoolVarP(&flagAddAutoCommit, "commit", "c",
 		true, "If true entries will be autocommited to the repository entries are in.")
+	cmdAdd.PersistentFlags().BoolVar(&flagAddNoCommit, "no-commit",
+		false, "Queue the entry instead of committing it. Queued entries are committed together with lablog commit.")
 
 	// note
+	cmdAddNote.Flags().BoolVarP(&flagAddNoteEditor, "editor", "e",
+		false, "Compose the note in $VISUAL or $EDITOR. This is the default if no value is given.")
 	cmdAdd.AddCommand(cmdAddNote)
 
 	// todo
@@ -70,11 +79,15 @@ func runCmdAdd(cmd *cobra.Command, args []string) {
 var cmdAddNote = &cobra.Command{
 	Use:   "note",
 	Short: "Add a new note to the log",
-	Long:  `Add a new note to the log which can have a timestamp and an free form value for text.`,
+	Long:  `Add a new note to the log which can have a timestamp and an free form value for text. If no value is given the note will be composed in $VISUAL or $EDITOR.`,
 	RunE:  runCmdAddNote,
 }
 
 func runCmdAddNote(cmd *cobra.Command, args []string) error {
+	if len(args) == 1 || flagAddNoteEditor {
+		return runCmdAddNoteEditor(args)
+	}
+
 	project, timestamp, value, err := helper.ArgsToEntryValues(args, flagAddTimeStamp, flagAddTimeStampRaw)
 	if err != nil {
 		return errgo.Notef(err, "can not convert args to entry usable values")
@@ -99,9 +112,68 @@ func runCmdAddNote(cmd *cobra.Command, args []string) error {
 		TimeStamp: timestamp,
 	}
 
-	err = helper.RecordEntry(flagDataDir, project, note, flagAddAutoCommit)
+	err = recordEntry(project, note)
+	if err != nil {
+		return errgo.Notef(err, "can not record note to store")
+	}
+
+	return nil
+}
+
+func runCmdAddNoteEditor(args []string) error {
+	if len(args) < 1 {
+		return errgo.New("need a project to run")
+	}
+
+	project, err := data.ParseProjectName(args[0])
+	if err != nil {
+		return errgo.Notef(err, "can not parse project name")
+	}
+
+	timestamp, err := helper.DefaultOrRawTimestamp(flagAddTimeStamp, flagAddTimeStampRaw)
+	if err != nil {
+		return errgo.Notef(err, "can not get timestamp")
+	}
+
+	header := []string{
+		"project: " + project.String(),
+		"timestamp: " + timestamp.Format(formatting.HeaderTimeFormat),
+		"Lines starting with this prefix are removed, an empty note aborts.",
+	}
+
+	value, err := helper.EditValue(header, strings.Join(args[1:], " "))
+	if err != nil {
+		return errgo.Notef(err, "can not compose note in editor")
+	}
+
+	note := data.Note{
+		Value:     value,
+		TimeStamp: timestamp,
+	}
+
+	err = recordEntry(project, note)
+	if err != nil {
+		return errgo.Notef(err, "can not record note to store")
+	}
+
+	return nil
+}
+
+// recordEntry records the entry and commits it or adds it to the queue if
+// --no-commit is set.
+func recordEntry(project data.ProjectName, entry data.Entry) error {
+	if !flagAddNoCommit {
+		return helper.RecordEntry(flagDataDir, project, entry, flagAddAutoCommit)
+	}
+
+	err := helper.RecordEntry(flagDataDir, project, entry, false)
+	if err != nil {
+		return err
+	}
+
+	err = vcs.Enqueue(flagDataDir, project, entry)
 	if err != nil {
-		errgo.Notef(err, "can not record note to store")
+		return errgo.Notef(err, "can not queue entry")
 	}
 
 	return nil
@@ -133,7 +205,7 @@ func runCmdAddTodoActive(cmd *cobra.Command, args []string) error {
 
 	todo.Active = true
 
-	err = helper.RecordEntry(flagDataDir, project, todo, flagAddAutoCommit)
+	err = recordEntry(project, todo)
 	if err != nil {
 		return errgo.Notef(err, "can not record todo to store")
 	}
@@ -156,9 +228,15 @@ func runCmdAddTodoInActive(cmd *cobra.Command, args []string) error {
 
 	todo.Active = false
 
-	err = helper.RecordEntry(flagDataDir, project, todo, flagAddAutoCommit)
+	// Marking a todo as inactive by its value closes the existing todo.
+	todo, err = helper.ExistingTodo(flagDataDir, project, todo)
 	if err != nil {
-		errgo.Notef(err, "can not record todo to store")
+		return errgo.Notef(err, "can not find existing todo")
+	}
+
+	err = recordEntry(project, todo)
+	if err != nil {
+		return errgo.Notef(err, "can not record todo to store")
 	}
 
 	return nil
@@ -0,0 +1,104 @@
+// Copyright © 2016 Alexander Thaller <alexander@thaller.ws>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/juju/errgo"
+
+	"github.com/spf13/cobra"
+)
+
+var flagArchiveRecursive bool
+var flagArchiveAutoCommit bool
+
+func init() {
+	for _, command := range []*cobra.Command{cmdArchive, cmdUnarchive} {
+		command.PersistentFlags().BoolVarP(&flagArchiveRecursive, "recursive", "r",
+			false, "Also move all subprojects of the project.")
+		command.PersistentFlags().BoolVarP(&flagArchiveAutoCommit, "commit", "c",
+			true, "If true the move will be autocommited to the repository entries are in.")
+	}
+
+	RootCmd.AddCommand(cmdArchive)
+	RootCmd.AddCommand(cmdUnarchive)
+}
+
+var cmdArchive = &cobra.Command{
+	Use:   "archive [project]",
+	Short: "Move a project into the archive",
+	Long:  `Move a project into the archive so it will not be shown anymore unless the archive flag is set.`,
+	RunE:  runCmdArchive,
+}
+
+func runCmdArchive(cmd *cobra.Command, args []string) error {
+	name, err := archiveNameFromArgs(args)
+	if err != nil {
+		return errgo.Notef(err, "can not get project name")
+	}
+
+	if name.IsArchived() {
+		return errgo.New("the project " + name.String() + " is already archived")
+	}
+
+	err = moveProjects(name, name.Archived(), flagArchiveRecursive,
+		"archive - "+name.String(), flagArchiveAutoCommit)
+	if err != nil {
+		return errgo.Notef(err, "can not archive project")
+	}
+
+	return nil
+}
+
+var cmdUnarchive = &cobra.Command{
+	Use:   "unarchive [project]",
+	Short: "Move a project out of the archive",
+	Long:  `Move a project out of the archive. The project can be given with or without the archive prefix.`,
+	RunE:  runCmdUnarchive,
+}
+
+func runCmdUnarchive(cmd *cobra.Command, args []string) error {
+	name, err := archiveNameFromArgs(args)
+	if err != nil {
+		return errgo.Notef(err, "can not get project name")
+	}
+
+	err = moveProjects(name.Archived(), name.Unarchived(), flagArchiveRecursive,
+		"unarchive - "+name.Unarchived().String(), flagArchiveAutoCommit)
+	if err != nil {
+		return errgo.Notef(err, "can not unarchive project")
+	}
+
+	return nil
+}
+
+func archiveNameFromArgs(args []string) (data.ProjectName, error) {
+	if len(args) != 1 {
+		return nil, errgo.New("need exactly one project to run")
+	}
+
+	name, err := data.ParseProjectName(args[0])
+	if err != nil {
+		return nil, errgo.Notef(err, "can not parse project name")
+	}
+
+	return name, nil
+}
@@ -0,0 +1,59 @@
+// Copyright © 2016 Alexander Thaller <alexander@thaller.ws>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AlexanderThaller/lablog/src/vcs"
+	"github.com/juju/errgo"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	RootCmd.AddCommand(cmdCommit)
+}
+
+var cmdCommit = &cobra.Command{
+	Use:   "commit",
+	Short: "Commit the queued entries",
+	Long: `Commit all changes of the datadir together with a message that lists how many
+entries were queued for every project. Entries are queued by lablog add
+--no-commit and by the webserver. Nothing is committed if the queue is empty.`,
+	RunE: runCmdCommit,
+}
+
+func runCmdCommit(cmd *cobra.Command, args []string) error {
+	message, err := vcs.Flush(flagDataDir)
+	if err != nil {
+		return errgo.Notef(err, "can not commit queued entries")
+	}
+
+	if message == "" {
+		fmt.Println("no queued entries to commit")
+		return nil
+	}
+
+	fmt.Println(message)
+
+	return nil
+}
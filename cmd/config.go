@@ -0,0 +1,73 @@
+// Copyright © 2016 Alexander Thaller <alexander@thaller.ws>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/AlexanderThaller/lablog/src/config"
+	"github.com/juju/errgo"
+	"github.com/mitchellh/go-homedir"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmdConfig.AddCommand(cmdConfigShow)
+
+	RootCmd.AddCommand(cmdConfig)
+}
+
+var cmdConfig = &cobra.Command{
+	Use:   "config [command]",
+	Short: "Inspect the configuration",
+	Long:  `The configuration is read from the config file of the user and the lablog.toml file in the datadir. Every value can be overridden with an environment variable like LABLOG_WEB_BINDING and with flags.`,
+	Run:   runCmdConfig,
+}
+
+func runCmdConfig(cmd *cobra.Command, args []string) {
+	cmd.Help()
+}
+
+var cmdConfigShow = &cobra.Command{
+	Use:   "show",
+	Short: "Show the current configuration",
+	Long:  `Show the configuration that results from the config files, the environment variables and the flags in the format of a config file.`,
+	RunE:  runCmdConfigShow,
+}
+
+func runCmdConfigShow(cmd *cobra.Command, args []string) error {
+	homepath, err := homedir.Dir()
+	if err != nil {
+		return errgo.Notef(err, "can not get homepath")
+	}
+
+	fmt.Println("# user config: " + config.UserPath(homepath))
+	fmt.Println("# datadir config: " + config.DataDirPath(currentConfig.DataDir))
+
+	err = currentConfig.Write(os.Stdout)
+	if err != nil {
+		return errgo.Notef(err, "can not write config")
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+// Copyright © 2016 Alexander Thaller <alexander@thaller.ws>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/formatting"
+	"github.com/AlexanderThaller/lablog/src/helper"
+	"github.com/juju/errgo"
+
+	"github.com/spf13/cobra"
+)
+
+var flagEditAutoCommit bool
+var flagEditEditor bool
+
+func init() {
+	cmdEdit.PersistentFlags().BoolVarP(&flagEditAutoCommit, "commit", "c",
+		true, "If true the change will be autocommited to the repository entries are in.")
+	cmdEdit.PersistentFlags().BoolVarP(&flagEditEditor, "editor", "e",
+		false, "Edit the current value in $VISUAL or $EDITOR. This is the default if no value is given.")
+
+	RootCmd.AddCommand(cmdEdit)
+}
+
+var cmdEdit = &cobra.Command{
+	Use:   "edit [project] [timestamp|todo id] [value]",
+	Short: "Change the value of an entry",
+	Long:  `Change the value of the entry with the given timestamp in the given project. The timestamp can be given like it is shown by the show commands. If the id of a todo is given all records of that todo will be changed. If no value is given the current value will be opened in $VISUAL or $EDITOR.`,
+	RunE:  runCmdEdit,
+}
+
+func runCmdEdit(cmd *cobra.Command, args []string) error {
+	if len(args) < 2 {
+		return errgo.New("need a project and a timestamp or todo id to run")
+	}
+
+	name, entries, err := entriesFromArgs(args[0], args[1])
+	if err != nil {
+		return errgo.Notef(err, "can not get entries to edit")
+	}
+
+	var value string
+	if len(args) == 2 || flagEditEditor {
+		value, err = editEntriesValue(name, entries)
+		if err != nil {
+			return errgo.Notef(err, "can not edit value in editor")
+		}
+	} else {
+		value = argsOrStdinValue(args[2:])
+	}
+
+	var changed []data.Entry
+	for _, entry := range entries {
+		changed = append(changed, entryWithValue(entry, value))
+	}
+
+	err = helper.UpdateEntries(flagDataDir, name, entries, changed, flagEditAutoCommit)
+	if err != nil {
+		return errgo.Notef(err, "can not update entries")
+	}
+
+	return nil
+}
+
+func editEntriesValue(name data.ProjectName, entries []data.Entry) (string, error) {
+	entry := entries[len(entries)-1]
+
+	header := []string{
+		"project: " + name.String(),
+		"type: " + entry.Type().String(),
+		"timestamp: " + entry.GetTimeStamp().Format(formatting.HeaderTimeFormat),
+		"Lines starting with this prefix are removed, an empty value aborts.",
+	}
+
+	return helper.EditValue(header, entryValue(entry)+"\n")
+}
+
+func argsOrStdinValue(args []string) string {
+	buffer := new(bytes.Buffer)
+
+	value := strings.Join(args, " ")
+	if value != "-" {
+		buffer.WriteString(value)
+	}
+
+	// If there is something piped in over stdin append it to the already set
+	// value
+
+	stat, _ := os.Stdin.Stat()
+	if (stat.Mode() & os.ModeCharDevice) == 0 {
+		io.Copy(buffer, os.Stdin)
+	}
+
+	return buffer.String()
+}
+
+// entriesFromArgs returns the entries of the given project that are referenced
+// by the given key. More than one entry is only returned if all of them are
+// records of the same todo.
+func entriesFromArgs(project, key string) (data.ProjectName, []data.Entry, error) {
+	name, err := data.ParseProjectName(project)
+	if err != nil {
+		return nil, nil, errgo.Notef(err, "can not parse project name")
+	}
+
+	store, err := helper.DefaultStore(flagDataDir)
+	if err != nil {
+		return nil, nil, errgo.Notef(err, "can not get data store")
+	}
+
+	filled, err := store.GetProject(name)
+	if err != nil {
+		return nil, nil, errgo.Notef(err, "can not get project")
+	}
+
+	entries, err := formatting.FindEntries(filled, key)
+	if err != nil {
+		return nil, nil, errgo.Notef(err, "can not find entries")
+	}
+
+	if len(entries) > 1 {
+		for _, entry := range entries {
+			todo, ok := entry.(data.Todo)
+			if !ok || filled.TodoID(todo) != key {
+				return nil, nil, errgo.New("more than one entry matches " + key + ", use the full timestamp")
+			}
+		}
+	}
+
+	return name, entries, nil
+}
+
+func entryValue(entry data.Entry) string {
+	switch entry := entry.(type) {
+	case data.Note:
+		return entry.Value
+	case data.Todo:
+		return entry.Value
+	default:
+		return ""
+	}
+}
+
+func entryWithValue(entry data.Entry, value string) data.Entry {
+	switch entry := entry.(type) {
+	case data.Note:
+		entry.Value = value
+		return entry
+	case data.Todo:
+		entry.Value = value
+		return entry
+	default:
+		return entry
+	}
+}
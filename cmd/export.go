@@ -0,0 +1,135 @@
+// Copyright © 2016 Alexander Thaller <alexander@thaller.ws>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/AlexanderThaller/lablog/src/asciidoc"
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/formatting"
+	"github.com/AlexanderThaller/lablog/src/helper"
+	"github.com/AlexanderThaller/lablog/src/web"
+	"github.com/juju/errgo"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagExportArchive  bool
+	flagExportRenderer string
+)
+
+func init() {
+	exportCmd.PersistentFlags().BoolVarP(&flagExportArchive, "archive", "a",
+		false, "If true archived projects will also be exported.")
+	exportHTMLCmd.Flags().StringVarP(&flagExportRenderer, "renderer", "r",
+		asciidoc.DefaultBackend, "The backend used to render asciidoc to html. Can be builtin or asciidoctor. Only asciidoctor passes passthrough blocks of notes through as html.")
+
+	exportCmd.AddCommand(exportHTMLCmd)
+	exportCmd.AddCommand(exportICSCmd)
+	exportCmd.AddCommand(exportTodoTxtCmd)
+	RootCmd.AddCommand(exportCmd)
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the entries to other formats",
+}
+
+var exportHTMLCmd = &cobra.Command{
+	Use:   "html <outdir>",
+	Short: "Export the entries as a static html site to the given folder",
+	Long: `Export the entries as a static html site to the given folder. The
+site contains an index page, pages for all projects and project subtrees and
+pages for every date entries were recorded on.`,
+	RunE: runExportHTML,
+}
+
+func runExportHTML(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errgo.New("need exactly one folder to export to")
+	}
+
+	err := web.Export(flagDataDir, args[0], flagExportArchive, flagExportRenderer)
+	if err != nil {
+		return errgo.Notef(err, "can not export html")
+	}
+
+	return nil
+}
+
+var exportICSCmd = &cobra.Command{
+	Use:   "ics [project]...",
+	Short: "Export notes and todos of the given projects as iCalendar",
+	Long: `Export the notes of the given projects as journal entries and the todos as
+tasks of an iCalendar. If no project is given all projects are exported.`,
+	RunE: runExportICS,
+}
+
+func runExportICS(cmd *cobra.Command, args []string) error {
+	projects, err := exportProjects(args)
+	if err != nil {
+		return err
+	}
+
+	err = formatting.ICS(os.Stdout, &projects)
+	if err != nil {
+		return errgo.Notef(err, "can not write icalendar")
+	}
+
+	return nil
+}
+
+var exportTodoTxtCmd = &cobra.Command{
+	Use:   "todotxt [project]...",
+	Short: "Export todos of the given projects in the todo.txt format",
+	Long: `Export the active and completed todos of the given projects in the todo.txt
+format. If no project is given all projects are exported.`,
+	RunE: runExportTodoTxt,
+}
+
+func runExportTodoTxt(cmd *cobra.Command, args []string) error {
+	projects, err := exportProjects(args)
+	if err != nil {
+		return err
+	}
+
+	err = formatting.TodoTxt(os.Stdout, &projects)
+	if err != nil {
+		return errgo.Notef(err, "can not write todo.txt")
+	}
+
+	return nil
+}
+
+func exportProjects(args []string) (data.Projects, error) {
+	store, err := helper.DefaultStore(flagDataDir)
+	if err != nil {
+		return data.Projects{}, errgo.Notef(err, "can not get data store")
+	}
+
+	projects, err := helper.ProjectsFromArgs(store, args, flagExportArchive)
+	if err != nil {
+		return data.Projects{}, errgo.Notef(err, "can not get projects")
+	}
+
+	return projects, nil
+}
@@ -0,0 +1,55 @@
+// Copyright © 2016 Alexander Thaller <alexander@thaller.ws>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/AlexanderThaller/lablog/src/vcs"
+	"github.com/juju/errgo"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	RootCmd.AddCommand(cmdGitMergeDriver)
+}
+
+var cmdGitMergeDriver = &cobra.Command{
+	Use:   "git-merge-driver [base] [ours] [theirs]",
+	Short: "Merge project files for git",
+	Long: `Merge three versions of a project file like git expects from a merge
+driver. The entries of both sides are combined, duplicates and entries that
+were removed on one side are dropped and the result is sorted by timestamp and
+written to the file of our version. Use lablog init to configure the driver.`,
+	RunE: runCmdGitMergeDriver,
+}
+
+func runCmdGitMergeDriver(cmd *cobra.Command, args []string) error {
+	if len(args) != 3 {
+		return errgo.New("need the base, our and their version of the file to run")
+	}
+
+	err := vcs.MergeFiles(args[0], args[1], args[2])
+	if err != nil {
+		return errgo.Notef(err, "can not merge files")
+	}
+
+	return nil
+}
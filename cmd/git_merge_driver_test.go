@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AlexanderThaller/dbfiles"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+// Test_ExecuteHelper runs lablog with the arguments from LABLOG_TEST_ARGS if
+// it is set. It is used to check the exit status of the process.
+func Test_ExecuteHelper(t *testing.T) {
+	args := os.Getenv("LABLOG_TEST_ARGS")
+	if args == "" {
+		return
+	}
+
+	os.Args = append([]string{"lablog"}, strings.Split(args, "\n")...)
+	Execute()
+	os.Exit(0)
+}
+
+// runLablog runs lablog with the arguments in a new process and returns the
+// exit status. The config of the user is read from the folder.
+func runLablog(t *testing.T, folder string, args ...string) int {
+	command := exec.Command(os.Args[0], "-test.run=^Test_ExecuteHelper$")
+	command.Env = append(os.Environ(),
+		"LABLOG_TEST_ARGS="+strings.Join(args, "\n"),
+		"XDG_CONFIG_HOME="+folder,
+	)
+
+	err := command.Run()
+	if err == nil {
+		return 0
+	}
+
+	exiterr, ok := err.(*exec.ExitError)
+	if !ok {
+		t.Fatal("can not run lablog: ", err)
+	}
+
+	return exiterr.ExitCode()
+}
+
+func Test_GitMergeDriverExitStatus(t *testing.T) {
+	folder, err := ioutil.TempDir("", "cmd_test_merge")
+	if err != nil {
+		t.Fatal("can not open tmpdir: ", err)
+	}
+
+	ours := new(bytes.Buffer)
+	dbfiles.CSV{}.Write(ours, testhelper.GetTestNote(0, "ours").Values())
+
+	files := map[string]string{
+		"base":    "",
+		"ours":    ours.String(),
+		"invalid": "unknown,entry\n",
+	}
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(folder, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal("can not write file: ", err)
+		}
+	}
+
+	path := func(name string) string {
+		return filepath.Join(folder, name)
+	}
+
+	status := runLablog(t, folder, "git-merge-driver", path("base"), path("ours"), path("invalid"))
+	testhelper.CompareGotExpected(t, nil, status, 1)
+
+	status = runLablog(t, folder, "git-merge-driver", path("base"), path("ours"), path("base"))
+	testhelper.CompareGotExpected(t, nil, status, 0)
+
+	merged, err := ioutil.ReadFile(path("ours"))
+	testhelper.CompareGotExpected(t, err, string(merged), ours.String())
+}
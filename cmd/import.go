@@ -0,0 +1,139 @@
+// Copyright © 2016 Alexander Thaller <alexander@thaller.ws>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/formatting"
+	"github.com/AlexanderThaller/lablog/src/helper"
+	"github.com/AlexanderThaller/lablog/src/importer"
+	"github.com/juju/errgo"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagImportAutoCommit bool
+	flagImportDryRun     bool
+	flagImportProject    string
+)
+
+func init() {
+	cmdImport.PersistentFlags().BoolVarP(&flagImportAutoCommit, "commit", "c",
+		true, "If true the imported entries will be autocommited to the repository entries are in.")
+	cmdImport.PersistentFlags().BoolVarP(&flagImportDryRun, "dry-run", "n",
+		false, "If true only show the entries that would be imported.")
+	cmdImport.PersistentFlags().StringVarP(&flagImportProject, "project", "p",
+		"", "The project for entries that do not name a project themselves.")
+
+	RootCmd.AddCommand(cmdImport)
+}
+
+var cmdImport = &cobra.Command{
+	Use:   "import [" + strings.Join(importer.Names(), "|") + "] [path]...",
+	Short: "Import entries from other formats",
+	Long: `Import entries from other formats. Entries that are already recorded
+on the same day with the same value are skipped and all imported entries are
+committed together.
+
+todotxt imports the tasks of todo.txt files as todos. The first +project of a
+task is used as the project.
+
+markdown imports journals in markdown where every heading starting with a date
+like "## 2016-05-20" or "## 2016-05-20 14:30" starts a new note.
+
+files imports every file as a note with the modification time of the file as
+timestamp. Folders are imported recursively.`,
+	RunE: runCmdImport,
+}
+
+func runCmdImport(cmd *cobra.Command, args []string) error {
+	if len(args) < 2 {
+		return errgo.New("need an importer and at least one path to run")
+	}
+
+	var options importer.Options
+	if flagImportProject != "" {
+		project, err := data.ParseProjectName(flagImportProject)
+		if err != nil {
+			return errgo.Notef(err, "can not parse project name")
+		}
+
+		options.Project = project
+	}
+
+	records, err := importer.Import(args[0], args[1:], options)
+	if err != nil {
+		return errgo.Notef(err, "can not read entries")
+	}
+
+	store, err := helper.DefaultStore(flagDataDir)
+	if err != nil {
+		return errgo.Notef(err, "can not get data store")
+	}
+
+	records, duplicates, err := importer.Save(store, records, flagImportDryRun)
+	if err != nil {
+		return errgo.Notef(err, "can not import entries")
+	}
+
+	if !flagImportDryRun {
+		if len(records) != 0 {
+			err = helper.RecordChanges(flagDataDir, store,
+				"import - "+args[0]+" - "+strconv.Itoa(len(records))+" entries", flagImportAutoCommit)
+			if err != nil {
+				return errgo.Notef(err, "can not record import of entries")
+			}
+		}
+
+		fmt.Printf("Imported %d entries, skipped %d duplicates\n", len(records), duplicates)
+		return nil
+	}
+
+	for _, record := range records {
+		value := strings.SplitN(importValue(record.Entry), "\n", 2)[0]
+
+		fmt.Println(record.Project.String() + " " + record.Entry.Type().String() + " " +
+			record.Entry.GetTimeStamp().Format(formatting.HeaderTimeFormat) + " " + value)
+	}
+	fmt.Printf("Would import %d entries, skip %d duplicates\n", len(records), duplicates)
+
+	return nil
+}
+
+func importValue(entry data.Entry) string {
+	switch entry := entry.(type) {
+	case data.Note:
+		return entry.Value
+	case data.Todo:
+		if entry.Active {
+			return "[ ] " + entry.Value
+		}
+
+		return "[x] " + entry.Value
+	default:
+		return ""
+	}
+}
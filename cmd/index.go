@@ -0,0 +1,71 @@
+// Copyright © 2016 Alexander Thaller <alexander@thaller.ws>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AlexanderThaller/lablog/src/helper"
+	"github.com/AlexanderThaller/lablog/src/index"
+	"github.com/juju/errgo"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmdIndex.AddCommand(cmdIndexRebuild)
+
+	RootCmd.AddCommand(cmdIndex)
+}
+
+var cmdIndex = &cobra.Command{
+	Use:   "index [command]",
+	Short: "Manage the search index",
+	Long:  `The search index is saved in the datadir and is used to speed up searches. It is updated when entries are added and refreshed before every search.`,
+	Run:   runCmdIndex,
+}
+
+func runCmdIndex(cmd *cobra.Command, args []string) {
+	cmd.Help()
+}
+
+var cmdIndexRebuild = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Rebuild the search index",
+	Long:  `Remove the search index and recreate it from all projects in the datadir.`,
+	RunE:  runCmdIndexRebuild,
+}
+
+func runCmdIndexRebuild(cmd *cobra.Command, args []string) error {
+	store, err := helper.DefaultStore(flagDataDir)
+	if err != nil {
+		return errgo.Notef(err, "can not get data store")
+	}
+
+	idx, err := index.Rebuild(flagDataDir, store)
+	if err != nil {
+		return errgo.Notef(err, "can not rebuild search index")
+	}
+
+	fmt.Printf("Indexed %d projects with %d tokens\n", len(idx.Projects), len(idx.Tokens))
+
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright © 2016 Alexander Thaller <alexander@thaller.ws>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+
+	"github.com/AlexanderThaller/lablog/src/config"
+	"github.com/AlexanderThaller/lablog/src/store"
+	"github.com/AlexanderThaller/lablog/src/vcs"
+	"github.com/juju/errgo"
+	"github.com/mitchellh/go-homedir"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagInitAutoCommit bool
+	flagInitConfig     bool
+)
+
+func init() {
+	cmdInit.PersistentFlags().BoolVarP(&flagInitAutoCommit, "commit", "c",
+		true, "If true the change will be autocommited to the repository entries are in.")
+	cmdInit.PersistentFlags().BoolVar(&flagInitConfig, "config",
+		false, "If true the current config is written to the config file in the datadir.")
+
+	RootCmd.AddCommand(cmdInit)
+}
+
+var cmdInit = &cobra.Command{
+	Use:   "init [dir]",
+	Short: "Create and setup a datadir",
+	Long: `Create the datadir, initialize the repository of the configured vcs backend
+in it and mark it with the layout version of the project files. With the git
+backend the repository is setup to merge project files with lablog
+git-merge-driver. If no folder is given the datadir is used. Running init in an
+existing datadir only adds what is missing.`,
+	RunE: runCmdInit,
+}
+
+func runCmdInit(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		return errgo.New("need at most one folder to run")
+	}
+
+	datadir := flagDataDir
+	if len(args) == 1 {
+		expanded, err := homedir.Expand(args[0])
+		if err != nil {
+			return errgo.Notef(err, "can not expand folder")
+		}
+
+		datadir = expanded
+	}
+
+	err := os.MkdirAll(datadir, 0755)
+	if err != nil {
+		return errgo.Notef(err, "can not create datadir")
+	}
+
+	backend, err := vcs.Open(datadir)
+	if err != nil {
+		return errgo.Notef(err, "can not open vcs backend")
+	}
+
+	err = backend.Init()
+	if err != nil {
+		return errgo.Notef(err, "can not init repository")
+	}
+
+	version, err := store.Layout(datadir)
+	if err != nil {
+		return errgo.Notef(err, "can not get layout version of datadir")
+	}
+
+	if version > store.LayoutVersion {
+		return errgo.New("datadir " + datadir + " has a newer layout than this version of lablog supports")
+	}
+
+	err = store.WriteLayout(datadir)
+	if err != nil {
+		return errgo.Notef(err, "can not write layout version")
+	}
+
+	if flagInitConfig {
+		buffer := new(bytes.Buffer)
+		err = currentConfig.WriteShared(buffer)
+		if err != nil {
+			return errgo.Notef(err, "can not write config")
+		}
+
+		err = ioutil.WriteFile(config.DataDirPath(datadir), buffer.Bytes(), 0644)
+		if err != nil {
+			return errgo.Notef(err, "can not write config file")
+		}
+	}
+
+	err = vcs.IgnoreQueue(datadir)
+	if err != nil {
+		return errgo.Notef(err, "can not ignore queue of entries")
+	}
+
+	// Only git runs merge drivers.
+	if vcs.BackendName == "git" {
+		err = vcs.InstallMergeDriver(datadir, "lablog")
+		if err != nil {
+			return errgo.Notef(err, "can not install merge driver")
+		}
+	}
+
+	if flagInitAutoCommit {
+		err = vcs.CommitMessage(datadir, "init")
+		if err != nil {
+			return errgo.Notef(err, "can not commit change to repository")
+		}
+	}
+
+	return nil
+}
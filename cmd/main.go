@@ -21,11 +21,17 @@
 package cmd
 
 import (
+	"os"
 	"path"
+	"strings"
 
 	log "github.com/Sirupsen/logrus"
 
+	"github.com/AlexanderThaller/lablog/src/config"
+	"github.com/AlexanderThaller/lablog/src/formatting"
 	"github.com/AlexanderThaller/lablog/src/helper"
+	"github.com/AlexanderThaller/lablog/src/store"
+	"github.com/AlexanderThaller/lablog/src/vcs"
 
 	"github.com/juju/errgo"
 	"github.com/mitchellh/go-homedir"
@@ -35,6 +41,49 @@ import (
 var flagDataDir string
 var flagLogLevel string
 
+// currentConfig is the config after applying the config files, environment
+// variables and flags.
+var currentConfig config.Config
+
+// configFlag maps a flag of a command and its subcommands to the config key
+// that sets its default. The command is the path of the command below the
+// root command, flags of an empty command belong to all commands.
+type configFlag struct {
+	Command string
+	Flag    string
+	Key     string
+}
+
+// configFlags are the flags that get their defaults from the config.
+var configFlags = []configFlag{
+	{"", "datadir", "datadir"},
+	{"", "loglevel", "loglevel"},
+	{"add", "commit", "autocommit"},
+	{"archive", "commit", "autocommit"},
+	{"unarchive", "commit", "autocommit"},
+	{"edit", "commit", "autocommit"},
+	{"import", "commit", "autocommit"},
+	{"project", "commit", "autocommit"},
+	{"rm", "commit", "autocommit"},
+	{"todo", "commit", "autocommit"},
+	{"web", "commit", "autocommit"},
+	{"web", "binding", "web.binding"},
+	{"web", "renderer", "web.renderer"},
+	{"web", "commit-delay", "web.commitdelay"},
+	{"show", "archive", "show.archive"},
+	{"show", "since", "show.since"},
+	{"show", "until", "show.until"},
+	{"show", "format", "show.format"},
+	{"show", "output", "show.output"},
+}
+
+// matches returns true if the flag belongs to the command.
+func (flag configFlag) matches(cmd *cobra.Command) bool {
+	path := strings.TrimSpace(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()))
+
+	return flag.Command == "" || path == flag.Command || strings.HasPrefix(path, flag.Command+" ")
+}
+
 func init() {
 	homepath, err := homedir.Dir()
 	helper.ErrExit(errgo.Notef(err, "can not get homepath"))
@@ -45,7 +94,7 @@ func init() {
 	RootCmd.PersistentFlags().StringVarP(&flagDataDir, "datadir", "d",
 		datadir, "The path to the datadir for retreiving and storing the data.")
 	RootCmd.PersistentFlags().StringVarP(&flagLogLevel, "loglevel", "l",
-		"info", "The loglevel for which to run in. Default is warn. There are panic, fatal, error, warn info and debug as levels.")
+		"info", "The loglevel for which to run in. There are panic, fatal, error, warn, info and debug as levels.")
 }
 
 // This represents the base command when called without any subcommands
@@ -54,14 +103,173 @@ var RootCmd = &cobra.Command{
 	Short:             "lablog makes taking notes and todos easy",
 	Long:              `lablog orders notes and todos into projects and subprojects without dictating a specific format.`,
 	RunE:              runCmdShowProjects,
-	PersistentPreRunE: setLogLevel,
+	PersistentPreRunE: runPersistentPreRun,
 }
 
+// Execute runs the command from the arguments. It exits with status 1 if the
+// command fails so callers like git notice the failure.
 func Execute() {
 	err := RootCmd.Execute()
 	if err != nil {
 		log.Debug(errgo.Details(err))
+		os.Exit(1)
+	}
+}
+
+func runPersistentPreRun(cmd *cobra.Command, args []string) error {
+	err := loadConfig(cmd)
+	if err != nil {
+		return errgo.Notef(err, "can not load config")
+	}
+
+	err = setLogLevel(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	return checkDataDir(cmd)
+}
+
+// checkDataDir returns an error if the command uses the datadir and the
+// datadir was not initialized with lablog init. If the command commits its
+// changes the datadir also has to contain the repository of the vcs backend.
+// This is checked before the command runs so no files are changed.
+func checkDataDir(cmd *cobra.Command) error {
+	switch cmd {
+	case cmdInit, cmdVersion, cmdConfig, cmdConfigShow, cmdGitMergeDriver:
+		return nil
+	}
+
+	if cmd.Name() == "help" {
+		return nil
+	}
+
+	version, err := store.Layout(flagDataDir)
+	if err != nil {
+		return errgo.Notef(err, "can not get layout version of datadir")
+	}
+
+	switch {
+	case version == 0:
+		return errgo.New("datadir " + flagDataDir + " is not initialized, run 'lablog init " +
+			flagDataDir + "' to set it up")
+	case version > store.LayoutVersion:
+		return errgo.New("datadir " + flagDataDir + " has a newer layout than this version of lablog supports, update lablog")
+	}
+
+	commits := cmd == cmdSync || cmd == cmdCommit
+	if flag := cmd.Flags().Lookup("commit"); flag != nil && flag.Value.String() == "true" {
+		commits = true
+	}
+
+	if cmd == cmdSync && vcs.BackendName != "git" {
+		return errgo.New("sync needs the git vcs backend but the datadir uses " + vcs.BackendName)
+	}
+
+	if !commits {
+		return nil
 	}
+
+	backend, err := vcs.Open(flagDataDir)
+	if err != nil {
+		return errgo.Notef(err, "can not open vcs backend")
+	}
+
+	_, err = backend.Log(1)
+	if err == nil {
+		return nil
+	}
+
+	// Only a missing repository can be fixed with lablog init.
+	if _, staterr := os.Stat(path.Join(flagDataDir, ".git")); os.IsNotExist(staterr) {
+		return errgo.New("datadir " + flagDataDir + " has no repository, run 'lablog init " +
+			flagDataDir + "' or disable autocommit with --commit=false")
+	}
+
+	return errgo.Notef(err, "can not read the "+vcs.BackendName+" repository of datadir "+
+		flagDataDir+", disable autocommit with --commit=false to add entries without committing")
+}
+
+// loadConfig reads the config of the user and the config in the datadir and
+// uses the values as defaults for all flags that were not set. Environment
+// variables override the config files and the config of the user overrides
+// the config in the datadir.
+func loadConfig(cmd *cobra.Command) error {
+	homepath, err := homedir.Dir()
+	if err != nil {
+		return errgo.Notef(err, "can not get homepath")
+	}
+
+	user, err := config.ReadFile(config.UserPath(homepath))
+	if err != nil {
+		return errgo.Notef(err, "can not read config of user")
+	}
+
+	currentConfig = config.Default()
+	currentConfig.DataDir = flagDataDir
+
+	// The datadir has to be known before the config in it can be read.
+	datadir := flagDataDir
+	if !cmd.Flags().Changed("datadir") {
+		if value, ok := currentConfig.Env()["datadir"]; ok {
+			datadir = value
+		} else if value, ok := user["datadir"]; ok {
+			datadir = value
+		}
+	}
+
+	datadir, err = homedir.Expand(datadir)
+	if err != nil {
+		return errgo.Notef(err, "can not expand datadir")
+	}
+
+	shared, err := config.ReadFile(config.DataDirPath(datadir))
+	if err != nil {
+		return errgo.Notef(err, "can not read config in datadir")
+	}
+	delete(shared, "datadir")
+
+	for _, values := range []map[string]string{shared, user, currentConfig.Env()} {
+		err = currentConfig.Apply(values)
+		if err != nil {
+			return errgo.Notef(err, "can not apply config values")
+		}
+	}
+
+	currentConfig.DataDir = datadir
+
+	for _, configFlag := range configFlags {
+		if !configFlag.matches(cmd) {
+			continue
+		}
+
+		name, key := configFlag.Flag, configFlag.Key
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			continue
+		}
+
+		if flag.Changed {
+			err = currentConfig.Set(key, flag.Value.String())
+		} else {
+			err = flag.Value.Set(currentConfig.Get(key))
+		}
+
+		if err != nil {
+			return errgo.Notef(err, "can not use config value for flag "+name)
+		}
+	}
+
+	formatting.HeaderTimeFormat = currentConfig.TimeFormat
+	formatting.HeaderAttributes = currentConfig.Asciidoc
+
+	_, err = vcs.OpenBackend(currentConfig.VCSBackend, datadir)
+	if err != nil {
+		return errgo.Notef(err, "can not use vcs backend from config")
+	}
+	vcs.BackendName = currentConfig.VCSBackend
+
+	return nil
 }
 
 func setLogLevel(cmd *cobra.Command, args []string) error {
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func Test_ConfigFlagsScope(t *testing.T) {
+	tests := map[string]map[string]string{
+		"show notes": {
+			"archive": "show.archive",
+			"since":   "show.since",
+			"commit":  "",
+		},
+		"export html": {
+			"archive":  "",
+			"renderer": "",
+		},
+		"search": {
+			"archive": "",
+		},
+		"web": {
+			"renderer": "web.renderer",
+			"commit":   "autocommit",
+		},
+		"add note": {
+			"commit":   "autocommit",
+			"datadir":  "datadir",
+			"loglevel": "loglevel",
+		},
+		"init": {
+			"commit": "",
+		},
+	}
+
+	for path, flags := range tests {
+		cmd, _, err := RootCmd.Find(strings.Split(path, " "))
+		if err != nil {
+			t.Fatal("can not find command "+path+": ", err)
+		}
+
+		got := make(map[string]string)
+		for name := range flags {
+			got[name] = ""
+			for _, flag := range configFlags {
+				if flag.Flag == name && flag.matches(cmd) {
+					got[name] = flag.Key
+				}
+			}
+		}
+
+		testhelper.CompareGotExpected(t, nil, got, flags)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright © 2016 Alexander Thaller <alexander@thaller.ws>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/helper"
+	"github.com/AlexanderThaller/lablog/src/store"
+	"github.com/juju/errgo"
+
+	"github.com/spf13/cobra"
+)
+
+var flagProjectAutoCommit bool
+
+func init() {
+	cmdProject.PersistentFlags().BoolVarP(&flagProjectAutoCommit, "commit", "c",
+		true, "If true the change will be autocommited to the repository entries are in.")
+
+	cmdProject.AddCommand(cmdProjectMv)
+	cmdProject.AddCommand(cmdProjectMerge)
+
+	RootCmd.AddCommand(cmdProject)
+}
+
+var cmdProject = &cobra.Command{
+	Use:   "project [command]",
+	Short: "Rename and merge projects",
+	Long:  `Rename projects or merge the entries of projects together. Subprojects are always moved or merged together with their project.`,
+	Run:   runCmdProject,
+}
+
+func runCmdProject(cmd *cobra.Command, args []string) {
+	cmd.Help()
+}
+
+var cmdProjectMv = &cobra.Command{
+	Use:   "mv [from] [to]",
+	Short: "Rename a project",
+	Long:  `Rename a project and all of its subprojects. Fails if a project with one of the new names already exists, use merge in that case.`,
+	RunE:  runCmdProjectMv,
+}
+
+func runCmdProjectMv(cmd *cobra.Command, args []string) error {
+	from, to, err := projectNamesFromArgs(args)
+	if err != nil {
+		return errgo.Notef(err, "can not get project names")
+	}
+
+	err = moveProjects(from, to, true, "move - "+from.String()+" to "+to.String(), flagProjectAutoCommit)
+	if err != nil {
+		return errgo.Notef(err, "can not move project")
+	}
+
+	return nil
+}
+
+var cmdProjectMerge = &cobra.Command{
+	Use:   "merge [source] [destination]",
+	Short: "Merge the entries of two projects",
+	Long:  `Merge the entries of the source project and its subprojects into the destination project and its subprojects. Identical entries are only kept once and the source projects are removed afterwards.`,
+	RunE:  runCmdProjectMerge,
+}
+
+func runCmdProjectMerge(cmd *cobra.Command, args []string) error {
+	from, to, err := projectNamesFromArgs(args)
+	if err != nil {
+		return errgo.Notef(err, "can not get project names")
+	}
+
+	dataStore, err := helper.DefaultStore(flagDataDir)
+	if err != nil {
+		return errgo.Notef(err, "can not get data store")
+	}
+
+	err = store.MergeProjects(dataStore, from, to)
+	if err != nil {
+		return errgo.Notef(err, "can not merge projects")
+	}
+
+	err = helper.RecordChanges(flagDataDir, dataStore,
+		"merge - "+from.String()+" into "+to.String(), flagProjectAutoCommit)
+	if err != nil {
+		return errgo.Notef(err, "can not record merge of projects")
+	}
+
+	return nil
+}
+
+// moveProjects moves the projects in the store of the datadir and records the
+// moves with the message.
+func moveProjects(from, to data.ProjectName, recursive bool, message string, commit bool) error {
+	dataStore, err := helper.DefaultStore(flagDataDir)
+	if err != nil {
+		return errgo.Notef(err, "can not get data store")
+	}
+
+	moves, err := store.MoveProjects(dataStore, from, to, recursive)
+	if err != nil {
+		return errgo.Notef(err, "can not move projects")
+	}
+
+	err = helper.RecordMoves(flagDataDir, dataStore, moves, message, commit)
+	if err != nil {
+		return errgo.Notef(err, "can not record moves")
+	}
+
+	return nil
+}
+
+func projectNamesFromArgs(args []string) (data.ProjectName, data.ProjectName, error) {
+	if len(args) != 2 {
+		return nil, nil, errgo.New("need exactly two projects to run")
+	}
+
+	from, err := data.ParseProjectName(args[0])
+	if err != nil {
+		return nil, nil, errgo.Notef(err, "can not parse first project name")
+	}
+
+	to, err := data.ParseProjectName(args[1])
+	if err != nil {
+		return nil, nil, errgo.Notef(err, "can not parse second project name")
+	}
+
+	return from, to, nil
+}
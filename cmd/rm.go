@@ -0,0 +1,62 @@
+// Copyright © 2016 Alexander Thaller <alexander@thaller.ws>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/AlexanderThaller/lablog/src/helper"
+	"github.com/juju/errgo"
+
+	"github.com/spf13/cobra"
+)
+
+var flagRmAutoCommit bool
+
+func init() {
+	cmdRm.PersistentFlags().BoolVarP(&flagRmAutoCommit, "commit", "c",
+		true, "If true the change will be autocommited to the repository entries are in.")
+
+	RootCmd.AddCommand(cmdRm)
+}
+
+var cmdRm = &cobra.Command{
+	Use:   "rm [project] [timestamp|todo id]",
+	Short: "Remove an entry",
+	Long:  `Remove the entry with the given timestamp from the given project. The timestamp can be given like it is shown by the show commands. If the id of a todo is given all records of that todo will be removed.`,
+	RunE:  runCmdRm,
+}
+
+func runCmdRm(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return errgo.New("need a project and a timestamp or todo id to run")
+	}
+
+	name, entries, err := entriesFromArgs(args[0], args[1])
+	if err != nil {
+		return errgo.Notef(err, "can not get entries to remove")
+	}
+
+	err = helper.DeleteEntries(flagDataDir, name, entries, flagRmAutoCommit)
+	if err != nil {
+		return errgo.Notef(err, "can not delete entries")
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright © 2016 Alexander Thaller <alexander@thaller.ws>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/AlexanderThaller/lablog/src/formatting"
+	"github.com/AlexanderThaller/lablog/src/helper"
+	"github.com/AlexanderThaller/lablog/src/index"
+	"github.com/AlexanderThaller/lablog/src/search"
+	"github.com/juju/errgo"
+
+	"github.com/spf13/cobra"
+)
+
+var flagSearchRegex bool
+var flagSearchIgnoreCase bool
+var flagSearchArchive bool
+
+func init() {
+	cmdSearch.PersistentFlags().BoolVarP(&flagSearchRegex, "regex", "r",
+		false, "Interpret the query as a regular expression.")
+	cmdSearch.PersistentFlags().BoolVarP(&flagSearchIgnoreCase, "ignore-case", "i",
+		false, "Ignore the case when matching the query.")
+	cmdSearch.PersistentFlags().BoolVarP(&flagSearchArchive, "archive", "a",
+		false, "Determines if entries from the archive will be searched. (default is false)")
+
+	RootCmd.AddCommand(cmdSearch)
+}
+
+var cmdSearch = &cobra.Command{
+	Use:   "search [query] [projects]",
+	Short: "Search notes and todos",
+	Long:  `Search the values of all notes and todos for the given query. If projects are given only those projects and their subprojects will be searched.`,
+	RunE:  runCmdSearch,
+}
+
+func runCmdSearch(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return errgo.New("need a query to search for")
+	}
+
+	query := search.Query{
+		Value:      args[0],
+		Regex:      flagSearchRegex,
+		IgnoreCase: flagSearchIgnoreCase,
+	}
+
+	store, err := helper.DefaultStore(flagDataDir)
+	if err != nil {
+		return errgo.Notef(err, "can not get data store")
+	}
+
+	projects, err := helper.ProjectNamesFromArgs(store, args[1:], flagSearchArchive)
+	if err != nil {
+		return errgo.Notef(err, "can not get list of projects")
+	}
+
+	results, err := index.Search(flagDataDir, store, query, projects)
+	if err != nil {
+		return errgo.Notef(err, "can not search projects")
+	}
+
+	formatting.SearchResults(os.Stdout, query.Value, 0, results)
+
+	return nil
+}
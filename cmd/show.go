@@ -20,13 +20,33 @@
 
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"os"
+	"time"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/formatting"
+	"github.com/AlexanderThaller/lablog/src/helper"
+	"github.com/juju/errgo"
+
+	"github.com/spf13/cobra"
+)
 
 var flagShowArchive bool
+var flagShowSince string
+var flagShowUntil string
+var flagShowFormat string
+var flagShowOutput string
 
 func init() {
 	cmdShow.PersistentFlags().BoolVarP(&flagShowArchive, "archive", "a",
 		false, "Determines if entries from the archive will be shown. (default is false)")
+	cmdShow.PersistentFlags().StringVarP(&flagShowSince, "since", "s",
+		"", "Only show entries recorded at or after this time. Accepts timestamps and relative values like 7d, yesterday or last-week.")
+	cmdShow.PersistentFlags().StringVarP(&flagShowUntil, "until", "u",
+		"", "Only show entries recorded before this time. Accepts timestamps and relative values like 7d, today or this-week.")
+	cmdShow.PersistentFlags().StringVarP(&flagShowOutput, "output", "o",
+		"", "Print machine readable output instead. Can be json, ndjson or csv.")
 
 	cmdShow.AddCommand(cmdShowTodos)
 
@@ -43,3 +63,48 @@ var cmdShow = &cobra.Command{
 func runCmdShow(cmd *cobra.Command, args []string) {
 	cmd.Help()
 }
+
+// addShowFormatFlag adds the format flag to show commands that print entries.
+func addShowFormatFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&flagShowFormat, "format", "f", formatting.DefaultFormat,
+		"The format the entries are printed in. Can be asciidoc or markdown.")
+}
+
+// showRecords writes the records to stdout in the output given by the output
+// flag.
+func showRecords(header []string, records []formatting.Record) error {
+	err := formatting.WriteRecords(os.Stdout, flagShowOutput, header, records)
+	if err != nil {
+		return errgo.Notef(err, "can not write records")
+	}
+
+	return nil
+}
+
+// showProjects will return the projects selected by the given args with their
+// entries filtered by the since and until flags.
+func showProjects(args []string) (data.Projects, error) {
+	reference := time.Now()
+
+	since, err := helper.ParseTimeFilter(flagShowSince, reference)
+	if err != nil {
+		return data.Projects{}, errgo.Notef(err, "can not parse since flag")
+	}
+
+	until, err := helper.ParseTimeFilter(flagShowUntil, reference)
+	if err != nil {
+		return data.Projects{}, errgo.Notef(err, "can not parse until flag")
+	}
+
+	store, err := helper.DefaultStore(flagDataDir)
+	if err != nil {
+		return data.Projects{}, errgo.Notef(err, "can not get data store")
+	}
+
+	projects, err := helper.ProjectsFromArgs(store, args, flagShowArchive)
+	if err != nil {
+		return data.Projects{}, errgo.Notef(err, "can not get projects")
+	}
+
+	return helper.FilterProjects(projects, since, until), nil
+}
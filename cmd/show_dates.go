@@ -22,8 +22,8 @@ package cmd
 
 import (
 	"fmt"
-	"sort"
 
+	"github.com/AlexanderThaller/lablog/src/formatting"
 	"github.com/AlexanderThaller/lablog/src/helper"
 	"github.com/juju/errgo"
 
@@ -42,42 +42,22 @@ var cmdShowDates = &cobra.Command{
 }
 
 func runCmdShowDates(cmd *cobra.Command, args []string) error {
-	store, err := helper.DefaultStore(flagDataDir)
+	projects, err := showProjects(args)
 	if err != nil {
-		return errgo.Notef(err, "can not get data store")
+		return errgo.Notef(err, "can not get projects")
 	}
 
-	projects, err := helper.ProjectNamesFromArgs(store, args, flagShowArchive)
-	if err != nil {
-		return errgo.Notef(err, "can not get list of projects")
-	}
-
-	err = store.PopulateProjects(&projects)
-	if err != nil {
-		return errgo.Notef(err, "can not populate projects with entries")
-	}
+	dates := helper.Dates(projects)
 
-	filter := make(map[string]struct{})
-	for _, project := range projects.List() {
-		entries := project.Entries
-
-		if len(entries) == 0 {
-			continue
+	if flagShowOutput != "" {
+		var records []formatting.Record
+		for _, date := range dates {
+			records = append(records, formatting.DateRecord{Date: date})
 		}
 
-		for _, entry := range entries {
-			date := entry.GetTimeStamp().Format("2006-01-02")
-			filter[date] = struct{}{}
-		}
+		return showRecords(formatting.DateRecordHeader, records)
 	}
 
-	var dates []string
-	for date := range filter {
-		dates = append(dates, date)
-	}
-
-	sort.Strings(dates)
-
 	for _, date := range dates {
 		fmt.Println(date)
 	}
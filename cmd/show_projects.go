@@ -23,6 +23,7 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/AlexanderThaller/lablog/src/formatting"
 	"github.com/AlexanderThaller/lablog/src/helper"
 	"github.com/juju/errgo"
 
@@ -51,6 +52,15 @@ func runCmdShowProjects(cmd *cobra.Command, args []string) error {
 		return errgo.Notef(err, "can not get list of projects")
 	}
 
+	if flagShowOutput != "" {
+		var records []formatting.Record
+		for _, project := range projects.List() {
+			records = append(records, formatting.ProjectRecord{Project: project.Name.String()})
+		}
+
+		return showRecords(formatting.ProjectRecordHeader, records)
+	}
+
 	for _, project := range projects.List() {
 		fmt.Println(project.Name)
 	}
@@ -24,13 +24,14 @@ import (
 	"os"
 
 	"github.com/AlexanderThaller/lablog/src/formatting"
-	"github.com/AlexanderThaller/lablog/src/helper"
 	"github.com/juju/errgo"
 
 	"github.com/spf13/cobra"
 )
 
 func init() {
+	addShowFormatFlag(cmdShowTodos)
+
 	cmdShow.AddCommand(cmdShowTodos)
 }
 
@@ -42,22 +43,22 @@ var cmdShowTodos = &cobra.Command{
 }
 
 func runCmdShowTodos(cmd *cobra.Command, args []string) error {
-	store, err := helper.DefaultStore(flagDataDir)
+	projects, err := showProjects(args)
 	if err != nil {
-		return errgo.Notef(err, "can not get data store")
+		return errgo.Notef(err, "can not get projects")
 	}
 
-	projects, err := helper.ProjectNamesFromArgs(store, args, flagShowArchive)
-	if err != nil {
-		return errgo.Notef(err, "can not get list of projects")
+	if flagShowOutput != "" {
+		return showRecords(formatting.EntryRecordHeader,
+			formatting.ProjectsRecords(&projects, false, true))
 	}
 
-	err = store.PopulateProjects(&projects)
+	formatter, err := formatting.ParseFormat(flagShowFormat)
 	if err != nil {
-		return errgo.Notef(err, "can not populate projects with entries")
+		return errgo.Notef(err, "can not get formatter")
 	}
 
-	formatting.ProjectsTodos(os.Stdout, "Todos", 0, &projects)
+	formatting.FormatProjectsTodos(os.Stdout, formatter, "Todos", 0, &projects)
 
 	return nil
 }
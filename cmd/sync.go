@@ -0,0 +1,66 @@
+// Copyright © 2016 Alexander Thaller <alexander@thaller.ws>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AlexanderThaller/lablog/src/vcs"
+	"github.com/juju/errgo"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	RootCmd.AddCommand(cmdSync)
+}
+
+var cmdSync = &cobra.Command{
+	Use:   "sync",
+	Short: "Pull, rebase and push the datadir",
+	Long: `Fetch the upstream branch of the datadir repository, rebase the local
+commits onto it and push them. Conflicts in projects are resolved by merging
+the entries of both sides ordered by their timestamp. The datadir must not have
+uncommitted changes, queued entries are committed before the sync.`,
+	RunE: runCmdSync,
+}
+
+func runCmdSync(cmd *cobra.Command, args []string) error {
+	_, err := vcs.Flush(flagDataDir)
+	if err != nil {
+		return errgo.Notef(err, "can not commit queued entries")
+	}
+
+	result, err := vcs.Sync(flagDataDir)
+	if err != nil {
+		return errgo.Notef(err, "can not sync datadir")
+	}
+
+	for _, project := range result.Updated {
+		fmt.Println("updated " + project.String())
+	}
+
+	for _, project := range result.Merged {
+		fmt.Println("merged " + project.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright © 2016 Alexander Thaller <alexander@thaller.ws>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/helper"
+	"github.com/juju/errgo"
+
+	"github.com/spf13/cobra"
+)
+
+var flagTodoTimeStamp time.Time
+var flagTodoTimeStampRaw string
+var flagTodoAutoCommit bool
+
+func init() {
+	flagTodoTimeStamp = time.Now()
+
+	cmdTodo.PersistentFlags().StringVarP(&flagTodoTimeStampRaw, "timestamp", "t",
+		flagTodoTimeStamp.String(), "The timestamp for which to record the state change.")
+	cmdTodo.PersistentFlags().BoolVarP(&flagTodoAutoCommit, "commit", "c",
+		true, "If true entries will be autocommited to the repository entries are in.")
+
+	cmdTodo.AddCommand(cmdTodoList)
+	cmdTodo.AddCommand(cmdTodoDone)
+	cmdTodo.AddCommand(cmdTodoReopen)
+
+	RootCmd.AddCommand(cmdTodo)
+}
+
+var cmdTodo = &cobra.Command{
+	Use:   "todo [command]",
+	Short: "Change the state of existing todos",
+	Long:  `List todos with their ids and mark them as done or reopen them. Every state change is recorded as a new entry in the log.`,
+	Run:   runCmdTodo,
+}
+
+func runCmdTodo(cmd *cobra.Command, args []string) {
+	cmd.Help()
+}
+
+var cmdTodoList = &cobra.Command{
+	Use:   "list [project]",
+	Short: "List active todos with their ids",
+	Long:  `List all active todos of the given projects and their subprojects together with the ids needed to change their state.`,
+	RunE:  runCmdTodoList,
+}
+
+func runCmdTodoList(cmd *cobra.Command, args []string) error {
+	store, err := helper.DefaultStore(flagDataDir)
+	if err != nil {
+		return errgo.Notef(err, "can not get data store")
+	}
+
+	projects, err := helper.ProjectsFromArgs(store, args, false)
+	if err != nil {
+		return errgo.Notef(err, "can not get projects")
+	}
+
+	for _, project := range projects.List() {
+		for _, todo := range project.Todos() {
+			if !todo.Active {
+				continue
+			}
+
+			fmt.Println(project.Name.String() + " " + project.TodoID(todo) + " " + todo.Value)
+		}
+	}
+
+	return nil
+}
+
+var cmdTodoDone = &cobra.Command{
+	Use:   "done [project] [id]",
+	Short: "Mark a todo as done",
+	Long:  `Mark the todo with the given id in the given project as done.`,
+	RunE:  runCmdTodoDone,
+}
+
+func runCmdTodoDone(cmd *cobra.Command, args []string) error {
+	err := recordTodoState(args, false)
+	if err != nil {
+		return errgo.Notef(err, "can not mark todo as done")
+	}
+
+	return nil
+}
+
+var cmdTodoReopen = &cobra.Command{
+	Use:   "reopen [project] [id]",
+	Short: "Reopen a todo",
+	Long:  `Mark the todo with the given id in the given project as active again.`,
+	RunE:  runCmdTodoReopen,
+}
+
+func runCmdTodoReopen(cmd *cobra.Command, args []string) error {
+	err := recordTodoState(args, true)
+	if err != nil {
+		return errgo.Notef(err, "can not reopen todo")
+	}
+
+	return nil
+}
+
+func recordTodoState(args []string, active bool) error {
+	if len(args) != 2 {
+		return errgo.New("need a project and a todo id to run")
+	}
+
+	name, err := data.ParseProjectName(args[0])
+	if err != nil {
+		return errgo.Notef(err, "can not parse project name")
+	}
+
+	timestamp, err := helper.DefaultOrRawTimestamp(flagTodoTimeStamp, flagTodoTimeStampRaw)
+	if err != nil {
+		return errgo.Notef(err, "can not get timestamp")
+	}
+
+	store, err := helper.DefaultStore(flagDataDir)
+	if err != nil {
+		return errgo.Notef(err, "can not get data store")
+	}
+
+	project, err := store.GetProject(name)
+	if err != nil {
+		return errgo.Notef(err, "can not get project")
+	}
+
+	todo, found := project.Todo(args[1])
+	if !found {
+		return errgo.New("can not find todo with the id " + args[1] + " in project " + name.String())
+	}
+
+	if todo.Active == active {
+		return nil
+	}
+
+	todo.Active = active
+	todo.TimeStamp = timestamp
+
+	err = helper.RecordEntry(flagDataDir, name, todo, flagTodoAutoCommit)
+	if err != nil {
+		return errgo.Notef(err, "can not record todo to store")
+	}
+
+	return nil
+}
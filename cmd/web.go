@@ -21,6 +21,9 @@
 package cmd
 
 import (
+	"time"
+
+	"github.com/AlexanderThaller/lablog/src/asciidoc"
 	"github.com/AlexanderThaller/lablog/src/web"
 	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errgo"
@@ -28,14 +31,20 @@ import (
 )
 
 var (
-	flagWebBinding string
+	flagWebBinding     string
+	flagWebRenderer    string
+	flagWebCommitDelay time.Duration
 )
 
 func init() {
 	webCmd.PersistentFlags().StringVarP(&flagWebBinding, "binding", "b",
-		":18080", "The address and port to bind the webserver to.")
+		"localhost:18080", "The address and port to bind the webserver to. Use :18080 to accept entries from other hosts.")
+	webCmd.PersistentFlags().StringVarP(&flagWebRenderer, "renderer", "r",
+		asciidoc.DefaultBackend, "The backend used to render asciidoc to html. Can be builtin or asciidoctor. Only asciidoctor passes passthrough blocks of notes through as html.")
 	webCmd.PersistentFlags().BoolVarP(&flagAddAutoCommit, "commit", "c",
 		true, "If true entries will be autocommited to the repository entries are in.")
+	webCmd.PersistentFlags().DurationVar(&flagWebCommitDelay, "commit-delay",
+		5*time.Second, "Entries added within this delay of each other are committed together. With 0 every entry is committed on its own.")
 
 	RootCmd.AddCommand(webCmd)
 }
@@ -52,7 +61,7 @@ func runWeb(cmd *cobra.Command, args []string) error {
 		return errgo.Notef(err, "can not parse loglevel from flag")
 	}
 
-	err = web.Listen(flagDataDir, flagWebBinding, level)
+	err = web.Listen(flagDataDir, flagWebBinding, level, flagAddAutoCommit, flagWebCommitDelay, flagWebRenderer)
 	if err != nil {
 		return errgo.Notef(err, "can not start web listener")
 	}
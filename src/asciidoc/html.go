@@ -0,0 +1,313 @@
+package asciidoc
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const stylesheet = `body { margin: 0 auto; max-width: 62.5em; padding: 0 1em; font-family: sans-serif; line-height: 1.5; color: #222; }
+h1, h2, h3, h4, h5, h6 { font-weight: normal; color: #ba3925; }
+a.anchor { position: absolute; margin-left: -1.2em; text-decoration: none; visibility: hidden; }
+a.anchor::before { content: "\00A7"; }
+h1:hover a.anchor, h2:hover a.anchor, h3:hover a.anchor, h4:hover a.anchor, h5:hover a.anchor, h6:hover a.anchor { visibility: visible; }
+a.link { color: inherit; text-decoration: none; }
+pre { background: #f7f7f8; padding: 1em; overflow-x: auto; }
+code { background: #f7f7f8; padding: 0 .2em; }
+pre code { padding: 0; }
+mark { background: #ffff80; }
+.title { font-style: italic; }
+body.toc2 { max-width: none; }
+body.toc-left { padding-left: 17em; }
+body.toc-right { padding-right: 17em; }
+#toc.toc2 { position: fixed; top: 0; width: 15em; height: 100%; overflow: auto; padding: 0 1em; background: #f8f8f7; border-right: 1px solid #e7e7e9; }
+body.toc-left #toc.toc2 { left: 0; }
+body.toc-right #toc.toc2 { right: 0; border-right: 0; border-left: 1px solid #e7e7e9; }
+#toc ul { list-style: none; padding-left: 1em; }
+`
+
+var (
+	regexInvalidID   = regexp.MustCompile(`[^\pL\pN_\s.-]`)
+	regexIDSeparator = regexp.MustCompile(`[\s.-]+`)
+)
+
+type renderer struct {
+	buffer *bytes.Buffer
+	doc    *document
+}
+
+func renderDocument(buffer *bytes.Buffer, doc *document) {
+	r := renderer{buffer: buffer, doc: doc}
+	r.prepare(&doc.Root, nil, make(map[string]int))
+
+	classes := []string{doc.Attributes["doctype"]}
+	toc, hasToc := doc.Attributes["toc"]
+	sidebar := toc == "left" || toc == "right"
+	if hasToc && sidebar {
+		classes = append(classes, "toc2", "toc-"+toc)
+	}
+
+	r.write("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"UTF-8\">\n")
+	r.write("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\">\n")
+	r.write("<title>" + escaper.Replace(doc.Title) + "</title>\n")
+	r.write("<style>\n" + stylesheet + "</style>\n</head>\n")
+	r.write("<body class=\"" + strings.Join(classes, " ") + "\">\n")
+
+	r.write("<div id=\"header\">\n")
+	if doc.Title != "" {
+		r.write("<h1>" + inline(doc.Title) + "</h1>\n")
+	}
+	if hasToc && hasSections(&doc.Root) {
+		class := "toc"
+		if sidebar {
+			class = "toc2"
+		}
+
+		r.write("<div id=\"toc\" class=\"" + class + "\">\n")
+		r.write("<div id=\"toctitle\">" + escaper.Replace(doc.Attributes["toc-title"]) + "</div>\n")
+		r.toc(&doc.Root, 1)
+		r.write("</div>\n")
+	}
+	r.write("</div>\n")
+
+	r.write("<div id=\"content\">\n")
+
+	// Content in front of the first section is the preamble.
+	blocks := doc.Root.Blocks
+	if hasSections(&doc.Root) {
+		var preamble []*block
+		for len(blocks) != 0 && blocks[0].Kind != blockSection {
+			preamble = append(preamble, blocks[0])
+			blocks = blocks[1:]
+		}
+
+		if len(preamble) != 0 {
+			r.write("<div id=\"preamble\">\n<div class=\"sectionbody\">\n")
+			r.blocks(preamble)
+			r.write("</div>\n</div>\n")
+		}
+	}
+	r.blocks(blocks)
+
+	r.write("</div>\n</body>\n</html>\n")
+}
+
+func (r renderer) write(value string) {
+	r.buffer.WriteString(value)
+}
+
+// prepare assigns the ids and the numbers of the sections.
+func (r renderer) prepare(parent *block, number []int, ids map[string]int) {
+	_, numbered := r.doc.Attributes["numbered"]
+	if _, ok := r.doc.Attributes["sectnums"]; ok {
+		numbered = true
+	}
+
+	counter := 0
+	for _, b := range parent.Blocks {
+		if b.Kind != blockSection {
+			continue
+		}
+
+		b.ID = r.sectionID(b.Title, ids)
+
+		current := number
+		if b.Level > 0 {
+			counter++
+			current = append(append([]int{}, number...), counter)
+
+			if numbered && len(current) <= r.attributeInt("sectnumlevels", 3) {
+				var parts []string
+				for _, value := range current {
+					parts = append(parts, strconv.Itoa(value))
+				}
+				b.Number = strings.Join(parts, ".") + ". "
+			}
+		}
+
+		r.prepare(b, current, ids)
+	}
+}
+
+// sectionID generates the id of a section from its title like asciidoctor
+// does. Duplicate ids get a counter appended.
+func (r renderer) sectionID(title string, ids map[string]int) string {
+	separator := r.doc.Attributes["idseparator"]
+
+	id := strings.ToLower(title)
+	id = regexInvalidID.ReplaceAllString(id, "")
+	id = regexIDSeparator.ReplaceAllString(id, separator)
+	if separator != "" {
+		id = strings.Trim(id, separator)
+	}
+	id = r.doc.Attributes["idprefix"] + id
+
+	ids[id]++
+	if ids[id] > 1 {
+		id += separator + strconv.Itoa(ids[id])
+	}
+
+	// The prefix and separator are attributes of the document which can
+	// contain any character.
+	return escaper.Replace(id)
+}
+
+func (r renderer) attributeInt(name string, fallback int) int {
+	value, err := strconv.Atoi(r.doc.Attributes[name])
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
+
+func hasSections(parent *block) bool {
+	for _, b := range parent.Blocks {
+		if b.Kind == blockSection {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r renderer) toc(parent *block, level int) {
+	if level > r.attributeInt("toclevels", 2) || !hasSections(parent) {
+		return
+	}
+
+	r.write("<ul class=\"sectlevel" + strconv.Itoa(level) + "\">\n")
+	for _, b := range parent.Blocks {
+		if b.Kind != blockSection {
+			continue
+		}
+
+		r.write("<li><a href=\"#" + b.ID + "\">" + b.Number + inline(b.Title) + "</a>\n")
+		r.toc(b, level+1)
+		r.write("</li>\n")
+	}
+	r.write("</ul>\n")
+}
+
+func (r renderer) blocks(blocks []*block) {
+	for _, b := range blocks {
+		r.block(b)
+	}
+}
+
+func (r renderer) block(b *block) {
+	switch b.Kind {
+	case blockSection:
+		r.section(b)
+	case blockParagraph:
+		r.write("<div class=\"paragraph\">\n")
+		r.title(b)
+		r.write("<p>" + inline(strings.Join(b.Lines, "\n")) + "</p>\n</div>\n")
+	case blockLiteral:
+		r.write("<div class=\"literalblock\">\n")
+		r.title(b)
+		r.write("<div class=\"content\">\n<pre>" + escaper.Replace(strings.Join(b.Lines, "\n")) + "</pre>\n</div>\n</div>\n")
+	case blockListing:
+		r.write("<div class=\"listingblock\">\n")
+		r.title(b)
+		r.write("<div class=\"content\">\n")
+
+		content := escaper.Replace(strings.Join(b.Lines, "\n"))
+		if b.Style == "source" && b.Language != "" {
+			r.write("<pre class=\"highlight\"><code class=\"language-" + escaper.Replace(b.Language) +
+				"\" data-lang=\"" + escaper.Replace(b.Language) + "\">" + content + "</code></pre>\n")
+		} else {
+			r.write("<pre>" + content + "</pre>\n")
+		}
+
+		r.write("</div>\n</div>\n")
+	case blockPassthrough:
+		// The content of passthrough blocks is escaped like a literal block so
+		// notes can not add html to the page.
+		r.write("<div class=\"literalblock\">\n")
+		r.title(b)
+		r.write("<div class=\"content\">\n<pre>" + escaper.Replace(strings.Join(b.Lines, "\n")) + "</pre>\n</div>\n</div>\n")
+	case blockRule:
+		r.write("<hr>\n")
+	case blockList:
+		r.list(b)
+	}
+}
+
+func (r renderer) title(b *block) {
+	if b.Title != "" {
+		r.write("<div class=\"title\">" + inline(b.Title) + "</div>\n")
+	}
+}
+
+func (r renderer) section(b *block) {
+	var title string
+	if _, ok := r.doc.Attributes["sectanchors"]; ok {
+		title += "<a class=\"anchor\" href=\"#" + b.ID + "\"></a>"
+	}
+
+	if _, ok := r.doc.Attributes["sectlinks"]; ok {
+		title += "<a class=\"link\" href=\"#" + b.ID + "\">" + b.Number + inline(b.Title) + "</a>"
+	} else {
+		title += b.Number + inline(b.Title)
+	}
+
+	if b.Level == 0 {
+		r.write("<h1 id=\"" + b.ID + "\" class=\"sect0\">" + title + "</h1>\n")
+		r.blocks(b.Blocks)
+		return
+	}
+
+	heading := "h" + strconv.Itoa(min(b.Level+1, 6))
+	level := strconv.Itoa(min(b.Level, 6))
+
+	r.write("<div class=\"sect" + level + "\">\n")
+	r.write("<" + heading + " id=\"" + b.ID + "\">" + title + "</" + heading + ">\n")
+
+	if b.Level == 1 {
+		r.write("<div class=\"sectionbody\">\n")
+	}
+
+	r.blocks(b.Blocks)
+
+	if b.Level == 1 {
+		r.write("</div>\n")
+	}
+
+	r.write("</div>\n")
+}
+
+func (r renderer) list(b *block) {
+	if b.Ordered {
+		r.write("<div class=\"olist arabic\">\n")
+		r.title(b)
+		r.write("<ol class=\"arabic\">\n")
+	} else {
+		r.write("<div class=\"ulist\">\n")
+		r.title(b)
+		r.write("<ul>\n")
+	}
+
+	for _, item := range b.Items {
+		r.write("<li>\n<p>" + inline(item.Text) + "</p>\n")
+		if item.Nested != nil {
+			r.list(item.Nested)
+		}
+		r.write("</li>\n")
+	}
+
+	if b.Ordered {
+		r.write("</ol>\n</div>\n")
+	} else {
+		r.write("</ul>\n</div>\n")
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,136 @@
+package asciidoc
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// escaper escapes text for html content and quoted attribute values.
+var escaper = strings.NewReplacer("\x00", "", "&", "&amp;", "<", "&lt;", ">", "&gt;",
+	`"`, "&quot;", "'", "&#39;")
+
+// linkSchemes are the schemes links can use. Links without a scheme are
+// relative and always allowed.
+var linkSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+var (
+	regexCode      = regexp.MustCompile("`([^`\n]+)`")
+	regexLinkMacro = regexp.MustCompile(`(?:link:(\S+?)|(https?://[^\s\[<>]+))\[([^\]\n]*)\]`)
+	regexBareURL   = regexp.MustCompile(`https?://[^\s\[\]<>]*[^\s\[\]<>.,;:!?)]`)
+	regexLineBreak = regexp.MustCompile(`(?m) \+$`)
+	regexHolder    = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// quotes are the unconstrained and constrained forms of the inline markup in
+// the order they are applied. Constrained markup has to be surrounded by
+// whitespace or punctuation.
+var quotes = []struct {
+	regex *regexp.Regexp
+	tag   string
+}{
+	{regexp.MustCompile(`##(.+?)##`), "mark"},
+	{regexp.MustCompile(`\*\*(.+?)\*\*`), "strong"},
+	{regexp.MustCompile(`__(.+?)__`), "em"},
+	{constrained("#"), "mark"},
+	{constrained(`\*`), "strong"},
+	{constrained("_"), "em"},
+}
+
+func constrained(mark string) *regexp.Regexp {
+	// Quotes are already escaped so the opening character can be the end of
+	// &quot; or &#39;.
+	return regexp.MustCompile(`(^|[\s(\[>;])` + mark + `(\S|\S.*?\S)` + mark + `($|[\s)\].,;:!?<&])`)
+}
+
+// safeLink returns true if the escaped link target is relative or uses one of
+// the linkSchemes. Everything before the first colon is the scheme unless a
+// slash, question mark or hash comes first.
+func safeLink(target string) bool {
+	end := strings.IndexAny(target, ":/?#")
+	if end == -1 || target[end] != ':' {
+		return true
+	}
+
+	return linkSchemes[strings.ToLower(target[:end])]
+}
+
+// inline escapes the text and converts the inline markup to html. Code spans
+// and links are replaced by placeholders first so their content is not
+// formatted.
+func inline(text string) string {
+	text = escaper.Replace(text)
+
+	var holders []string
+	hold := func(value string) string {
+		holders = append(holders, value)
+		return "\x00" + strconv.Itoa(len(holders)-1) + "\x00"
+	}
+
+	text = regexCode.ReplaceAllStringFunc(text, func(match string) string {
+		return hold("<code>" + regexCode.FindStringSubmatch(match)[1] + "</code>")
+	})
+
+	text = regexLinkMacro.ReplaceAllStringFunc(text, func(match string) string {
+		values := regexLinkMacro.FindStringSubmatch(match)
+		target := values[1] + values[2]
+
+		label := values[3]
+		if !safeLink(target) {
+			if label == "" {
+				return hold(target)
+			}
+
+			return hold(label)
+		}
+
+		if label == "" {
+			return hold(`<a href="` + target + `" class="bare">` + target + `</a>`)
+		}
+
+		return hold(`<a href="` + target + `">` + label + `</a>`)
+	})
+
+	text = regexBareURL.ReplaceAllStringFunc(text, func(match string) string {
+		return hold(`<a href="` + match + `" class="bare">` + match + `</a>`)
+	})
+
+	for _, quote := range quotes {
+		open, close := "<"+quote.tag+">", "</"+quote.tag+">"
+
+		// Constrained markup shares the surrounding character with the
+		// next match so it has to be applied until nothing changes.
+		for {
+			replaced := quote.regex.ReplaceAllStringFunc(text, func(match string) string {
+				values := quote.regex.FindStringSubmatch(match)
+				if len(values) == 2 {
+					return open + values[1] + close
+				}
+
+				return values[1] + open + values[2] + close + values[3]
+			})
+
+			if replaced == text {
+				break
+			}
+			text = replaced
+		}
+	}
+
+	text = regexLineBreak.ReplaceAllString(text, "<br>")
+
+	// Link labels can contain code spans so the placeholders are restored
+	// until none are left.
+	for regexHolder.MatchString(text) {
+		text = regexHolder.ReplaceAllStringFunc(text, func(match string) string {
+			index, _ := strconv.Atoi(regexHolder.FindStringSubmatch(match)[1])
+			return holders[index]
+		})
+	}
+
+	return text
+}
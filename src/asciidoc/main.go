@@ -0,0 +1,88 @@
+// Package asciidoc renders the asciidoc documents written by the formatting
+// package to html. The builtin renderer only supports the subset of asciidoc
+// that is used by lablog and in typical notes: the document header and
+// attributes, sections, paragraphs, lists, listing, literal and passthrough
+// blocks and the common inline markup. Passthrough blocks are escaped instead
+// of being passed through as html. The asciidoctor binary can be used as an
+// alternative backend if it is installed.
+package asciidoc
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/juju/errgo"
+)
+
+// Renderer reads an asciidoc document from the reader and writes the
+// rendered html document to the writer.
+type Renderer func(io.Reader, io.Writer) error
+
+// DefaultBackend is the name of the builtin renderer.
+const DefaultBackend = "builtin"
+
+// Backends contains all renderers by their name.
+var Backends = map[string]Renderer{
+	DefaultBackend: Render,
+	"asciidoctor":  Asciidoctor,
+}
+
+// Backend returns the renderer with the given name.
+func Backend(name string) (Renderer, error) {
+	renderer, ok := Backends[name]
+	if !ok {
+		var names []string
+		for name := range Backends {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return nil, errgo.New("unknown asciidoc backend " + name +
+			", possible backends are " + strings.Join(names, ", "))
+	}
+
+	return renderer, nil
+}
+
+// Render renders the document with the builtin renderer.
+func Render(reader io.Reader, writer io.Writer) error {
+	raw, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return errgo.Notef(err, "can not read document")
+	}
+
+	doc := parse(string(raw))
+
+	buffer := new(bytes.Buffer)
+	renderDocument(buffer, doc)
+
+	_, err = buffer.WriteTo(writer)
+	if err != nil {
+		return errgo.Notef(err, "can not write html")
+	}
+
+	return nil
+}
+
+// Asciidoctor renders the document by running the asciidoctor binary. Unlike
+// the builtin renderer asciidoctor passes passthrough blocks through as html
+// so it should only be used for trusted notes.
+func Asciidoctor(reader io.Reader, writer io.Writer) error {
+	stderr := new(bytes.Buffer)
+
+	command := exec.Command("asciidoctor", "-")
+	command.Stdin = reader
+	command.Stdout = writer
+	command.Stderr = stderr
+
+	err := command.Run()
+	if err != nil {
+		return errgo.Notef(err, "can not run asciidoctor: %s", stderr.String())
+	}
+
+	return nil
+}
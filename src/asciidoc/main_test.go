@@ -0,0 +1,200 @@
+package asciidoc
+
+import (
+	"bytes"
+	"flag"
+	"html"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/AlexanderThaller/lablog/src/formatting"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func compareGolden(t *testing.T, golden string, input io.Reader) {
+	got := new(bytes.Buffer)
+	err := Render(input, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *update {
+		err = ioutil.WriteFile(golden, got.Bytes(), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testhelper.CompareGotExpected(t, nil, got.String(), string(expected))
+}
+
+func Test_RenderGolden(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.adoc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		input, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		compareGolden(t, strings.TrimSuffix(file, ".adoc")+".html", bytes.NewReader(input))
+	}
+}
+
+func Test_RenderFormatting(t *testing.T) {
+	projects := testhelper.GetTestProjects(2, 2, "A", "B")
+
+	headers := testhelper.GetTestProject("C", 0, 1)
+	headers.AddNote(testhelper.GetTestNote(0, `= Header In Note Value
+note *note* note
+
+== SubHeader In Note Value
+note note note`))
+	projects.Add(headers)
+
+	tests := map[string]func(io.Writer){
+		"projects": func(writer io.Writer) {
+			formatting.Projects(writer, "Entries", 0, &projects)
+		},
+		"notes": func(writer io.Writer) {
+			formatting.ProjectsNotes(writer, "Notes", 0, &projects)
+		},
+		"todos": func(writer io.Writer) {
+			formatting.ProjectsTodos(writer, "Todos", 0, &projects)
+		},
+	}
+
+	for name, format := range tests {
+		input := new(bytes.Buffer)
+		format(input)
+
+		compareGolden(t, filepath.Join("testdata", "formatting_"+name+".html"), input)
+	}
+}
+
+func Test_RenderPassthroughEscaped(t *testing.T) {
+	project := testhelper.GetTestProject("A", 0, 0)
+	project.AddNote(testhelper.GetTestNote(0, "++++\n<script>alert(1)</script>\n++++"))
+
+	input := new(bytes.Buffer)
+	formatting.Project(input, 1, &project)
+
+	got := new(bytes.Buffer)
+	err := Render(input, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(got.String(), "<script>") {
+		t.Fatal("expected the passthrough block to be escaped but got: ", got.String())
+	}
+
+	if !strings.Contains(got.String(), "&lt;script&gt;alert(1)&lt;/script&gt;") {
+		t.Fatal("expected the escaped passthrough block but got: ", got.String())
+	}
+}
+
+// Test_RenderAsciidoctor compares the builtin renderer with asciidoctor if
+// it is installed. The html of both differs in details like classes and
+// whitespace, so only the text of the content is compared. Passthrough
+// blocks are removed from the documents as asciidoctor does not escape them.
+func Test_RenderAsciidoctor(t *testing.T) {
+	_, err := exec.LookPath("asciidoctor")
+	if err != nil {
+		t.Skip("asciidoctor is not installed")
+	}
+
+	inputs := make(map[string][]byte)
+
+	files, err := filepath.Glob("testdata/*.adoc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		input, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		inputs[file] = input
+	}
+
+	projects := testhelper.GetTestProjects(2, 2, "A", "B")
+	input := new(bytes.Buffer)
+	formatting.Projects(input, "Entries", 0, &projects)
+	inputs["formatting"] = input.Bytes()
+
+	for name, input := range inputs {
+		input = regexPassthrough.ReplaceAll(input, nil)
+
+		builtin := new(bytes.Buffer)
+		err := Render(bytes.NewReader(input), builtin)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		asciidoctor := new(bytes.Buffer)
+		err = Asciidoctor(bytes.NewReader(input), asciidoctor)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, expected := contentText(builtin.String()), contentText(asciidoctor.String())
+		if got != expected {
+			t.Errorf("%s: the text of the builtin renderer differs from asciidoctor\n"+
+				"builtin:     %s\nasciidoctor: %s", name, got, expected)
+		}
+	}
+}
+
+var (
+	regexPassthrough = regexp.MustCompile(`(?ms)^\+\+\+\+$.*?^\+\+\+\+$\n?`)
+	regexTag         = regexp.MustCompile(`<[^>]*>`)
+)
+
+// contentText returns the text of the content of the html document with all
+// whitespace collapsed to single spaces.
+func contentText(document string) string {
+	start := strings.Index(document, `<div id="content">`)
+	if start == -1 {
+		return ""
+	}
+	document = document[start:]
+
+	// asciidoctor writes a footer after the content.
+	if end := strings.Index(document, `<div id="footer">`); end != -1 {
+		document = document[:end]
+	}
+
+	text := html.UnescapeString(regexTag.ReplaceAllString(document, " "))
+
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func Test_Backend(t *testing.T) {
+	_, err := Backend(DefaultBackend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Backend("unkown")
+	if err == nil {
+		t.Fatal("expected an error for an unkown backend")
+	}
+}
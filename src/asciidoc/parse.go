@@ -0,0 +1,360 @@
+package asciidoc
+
+import (
+	"regexp"
+	"strings"
+)
+
+type blockKind int
+
+const (
+	blockParagraph blockKind = iota
+	blockLiteral
+	blockListing
+	blockPassthrough
+	blockList
+	blockRule
+	blockSection
+)
+
+// block is one element of the document. Sections contain the blocks up to the
+// next section of the same or a higher level.
+type block struct {
+	Kind  blockKind
+	Title string
+	// Style is the first positional attribute of the block like source.
+	Style    string
+	Language string
+	Lines    []string
+
+	Ordered bool
+	Items   []listItem
+
+	Level  int
+	ID     string
+	Number string
+	Blocks []*block
+}
+
+type listItem struct {
+	Text   string
+	Nested *block
+}
+
+type document struct {
+	Attributes map[string]string
+	Title      string
+	Root       block
+}
+
+var (
+	regexAttribute = regexp.MustCompile(`^:(!?)(\w[\w-]*)(!?):(?:\s+(.*))?$`)
+	regexHeading   = regexp.MustCompile(`^(=+)\s+(\S.*?)\s*$`)
+	regexListItem  = regexp.MustCompile(`^\s*(\*+|-|\.+)\s+(\S.*)$`)
+	regexTitle     = regexp.MustCompile(`^\.([^\s.].*)$`)
+	regexBlockAttr = regexp.MustCompile(`^\[(.*)\]$`)
+)
+
+// defaultAttributes are the attributes asciidoctor uses if a document does
+// not set them.
+func defaultAttributes() map[string]string {
+	return map[string]string{
+		"doctype":       "article",
+		"idprefix":      "_",
+		"idseparator":   "_",
+		"toclevels":     "2",
+		"sectnumlevels": "3",
+		"toc-title":     "Table of Contents",
+	}
+}
+
+type flatItem struct {
+	Marker string
+	Text   string
+}
+
+type parser struct {
+	lines []string
+	pos   int
+	doc   *document
+	stack []*block
+
+	title string
+	style string
+	lang  string
+}
+
+func parse(raw string) *document {
+	raw = strings.Replace(raw, "\r\n", "\n", -1)
+
+	doc := &document{
+		Attributes: defaultAttributes(),
+		Root:       block{Kind: blockSection, Level: -1},
+	}
+
+	p := &parser{
+		lines: strings.Split(raw, "\n"),
+		doc:   doc,
+	}
+	p.stack = []*block{&doc.Root}
+
+	for p.pos < len(p.lines) {
+		p.next()
+	}
+
+	return doc
+}
+
+func (p *parser) add(b *block) {
+	b.Title = p.title
+	if b.Style == "" {
+		b.Style = p.style
+	}
+	b.Language = p.lang
+	p.title, p.style, p.lang = "", "", ""
+
+	parent := p.stack[len(p.stack)-1]
+	parent.Blocks = append(parent.Blocks, b)
+}
+
+func (p *parser) next() {
+	line := strings.TrimRight(p.lines[p.pos], " \t")
+
+	switch {
+	case line == "":
+		p.pos++
+	case line == "////":
+		p.delimited(line)
+	case strings.HasPrefix(line, "//"):
+		p.pos++
+	case regexAttribute.MatchString(line):
+		match := regexAttribute.FindStringSubmatch(line)
+		if match[1] == "!" || match[3] == "!" {
+			delete(p.doc.Attributes, match[2])
+		} else {
+			p.doc.Attributes[match[2]] = match[4]
+		}
+		p.pos++
+	case line == "----", line == "....", line == "++++":
+		lines := p.delimited(line)
+
+		b := &block{Lines: lines}
+		switch line {
+		case "----":
+			b.Kind = blockListing
+		case "....":
+			b.Kind = blockLiteral
+		case "++++":
+			b.Kind = blockPassthrough
+		}
+		p.add(b)
+	case line == "'''":
+		p.add(&block{Kind: blockRule})
+		p.pos++
+	case regexHeading.MatchString(line):
+		p.heading(line)
+		p.pos++
+	case regexTitle.MatchString(line):
+		p.title = regexTitle.FindStringSubmatch(line)[1]
+		p.pos++
+	case regexBlockAttr.MatchString(line):
+		attributes := strings.Split(regexBlockAttr.FindStringSubmatch(line)[1], ",")
+		p.style = strings.TrimSpace(attributes[0])
+		if len(attributes) > 1 {
+			p.lang = strings.TrimSpace(attributes[1])
+		}
+		p.pos++
+	case regexListItem.MatchString(line):
+		p.list()
+	default:
+		p.paragraph()
+	}
+}
+
+// delimited returns the lines up to the closing delimiter and moves behind
+// it. An unclosed block ends with the document.
+func (p *parser) delimited(delimiter string) []string {
+	var lines []string
+
+	p.pos++
+	for ; p.pos < len(p.lines); p.pos++ {
+		if strings.TrimRight(p.lines[p.pos], " \t") == delimiter {
+			p.pos++
+			break
+		}
+
+		lines = append(lines, p.lines[p.pos])
+	}
+
+	return lines
+}
+
+func (p *parser) heading(line string) {
+	match := regexHeading.FindStringSubmatch(line)
+	level := len(match[1]) - 1
+
+	if level == 0 && p.doc.Title == "" && len(p.doc.Root.Blocks) == 0 {
+		p.doc.Title = match[2]
+		return
+	}
+
+	for len(p.stack) > 1 && p.stack[len(p.stack)-1].Level >= level {
+		p.stack = p.stack[:len(p.stack)-1]
+	}
+
+	section := &block{Kind: blockSection, Level: level, Title: match[2]}
+	p.title = ""
+
+	parent := p.stack[len(p.stack)-1]
+	parent.Blocks = append(parent.Blocks, section)
+	p.stack = append(p.stack, section)
+}
+
+// paragraph collects the lines up to the next blank line. Headings and block
+// delimiters also end a paragraph as lablog writes them without a blank line
+// in front. A paragraph that starts indented is a literal paragraph.
+func (p *parser) paragraph() {
+	var lines []string
+
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := strings.TrimRight(p.lines[p.pos], " \t")
+		if line == "" || (len(lines) != 0 && endsParagraph(line)) {
+			break
+		}
+
+		lines = append(lines, line)
+	}
+
+	kind := blockParagraph
+	if strings.HasPrefix(lines[0], " ") || strings.HasPrefix(lines[0], "\t") {
+		kind = blockLiteral
+		lines = dedent(lines)
+	}
+
+	p.add(&block{Kind: kind, Lines: lines})
+}
+
+func endsParagraph(line string) bool {
+	switch line {
+	case "----", "....", "++++", "////":
+		return true
+	}
+
+	return regexHeading.MatchString(line)
+}
+
+// startsBlock returns true if the line can not continue a list item. Like in
+// asciidoctor a comment line ends a list.
+func startsBlock(line string) bool {
+	if endsParagraph(line) || line == "'''" || strings.HasPrefix(line, "//") {
+		return true
+	}
+
+	return regexAttribute.MatchString(line) || regexBlockAttr.MatchString(line) ||
+		regexTitle.MatchString(line)
+}
+
+// list collects consecutive list items. Lines that are not list items are
+// added to the text of the previous item. A blank line only ends the list if
+// the next line is not a list item.
+func (p *parser) list() {
+	var items []flatItem
+
+	for p.pos < len(p.lines) {
+		line := strings.TrimRight(p.lines[p.pos], " \t")
+
+		if line == "" {
+			next := p.pos + 1
+			for next < len(p.lines) && strings.TrimSpace(p.lines[next]) == "" {
+				next++
+			}
+
+			if next < len(p.lines) && regexListItem.MatchString(p.lines[next]) {
+				p.pos = next
+				continue
+			}
+
+			break
+		}
+
+		if match := regexListItem.FindStringSubmatch(line); match != nil {
+			items = append(items, flatItem{Marker: match[1], Text: match[2]})
+			p.pos++
+			continue
+		}
+
+		if startsBlock(line) {
+			break
+		}
+
+		items[len(items)-1].Text += "\n" + strings.TrimSpace(line)
+		p.pos++
+	}
+
+	list, _ := buildList(items, nil)
+	p.add(&list)
+}
+
+// buildList nests the items by their markers. Items with the marker of the
+// first item are in the list, items with a marker that is not used by the
+// list or one of its parents start a nested list.
+func buildList(items []flatItem, parents []string) (block, int) {
+	marker := items[0].Marker
+	list := block{Kind: blockList, Ordered: strings.HasPrefix(marker, ".")}
+
+	nested := append(append([]string{}, parents...), marker)
+
+	i := 0
+	for i < len(items) {
+		item := items[i]
+
+		if item.Marker == marker {
+			list.Items = append(list.Items, listItem{Text: item.Text})
+			i++
+			continue
+		}
+
+		if contains(parents, item.Marker) {
+			break
+		}
+
+		child, count := buildList(items[i:], nested)
+		list.Items[len(list.Items)-1].Nested = &child
+		i += count
+	}
+
+	return list, i
+}
+
+func contains(values []string, value string) bool {
+	for _, current := range values {
+		if current == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func dedent(lines []string) []string {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		current := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent == -1 || current < indent {
+			indent = current
+		}
+	}
+
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) >= indent {
+			out[i] = line[indent:]
+		}
+	}
+
+	return out
+}
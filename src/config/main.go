@@ -0,0 +1,338 @@
+package config
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+// DataDirFileName is the name of the config file inside of the datadir which
+// can be shared between everyone using the datadir.
+const DataDirFileName = "lablog.toml"
+
+// EnvPrefix is the prefix of environment variables that override config
+// values. The key web.binding can be overridden with LABLOG_WEB_BINDING.
+const EnvPrefix = "LABLOG_"
+
+// Config contains all settings that can be set in a config file.
+type Config struct {
+	DataDir    string
+	LogLevel   string
+	AutoCommit bool
+
+	WebBinding string
+	// WebRenderer is the name of the backend used to render asciidoc to html
+	// in the webserver.
+	WebRenderer string
+	// WebCommitDelay is the time the webserver waits for more entries before
+	// it commits the added entries together. Every entry is committed on its
+	// own if it is 0.
+	WebCommitDelay time.Duration
+
+	ShowArchive bool
+	ShowSince   string
+	ShowUntil   string
+	ShowFormat  string
+	ShowOutput  string
+
+	TimeFormat string
+
+	// VCSBackend is the name of the backend that records the changes of the
+	// datadir.
+	VCSBackend string
+
+	// Asciidoc contains the document attributes written at the top of every
+	// asciidoc document in the order they are written.
+	Asciidoc [][2]string
+}
+
+// Default returns the config that is used if no config file is found.
+func Default() Config {
+	return Config{
+		LogLevel:       "info",
+		AutoCommit:     true,
+		WebBinding:     "localhost:18080",
+		WebRenderer:    "builtin",
+		WebCommitDelay: 5 * time.Second,
+		ShowFormat:     "asciidoc",
+		TimeFormat:     "2006-01-02 15:04:05",
+		VCSBackend:     "git",
+		Asciidoc: [][2]string{
+			{"toc", "right"},
+			{"toclevels", "4"},
+			{"sectanchors", ""},
+			{"sectlink", ""},
+			{"icons", "font"},
+			{"linkattrs", ""},
+			{"numbered", ""},
+			{"idprefix", ""},
+			{"idseparator", "-"},
+			{"doctype", "book"},
+			{"source-highlighter", "pygments"},
+			{"listing-caption", "Listing"},
+		},
+	}
+}
+
+// UserPath returns the path of the config file of the current user. It
+// respects $XDG_CONFIG_HOME and falls back to ~/.config.
+func UserPath(homedir string) string {
+	confighome := os.Getenv("XDG_CONFIG_HOME")
+	if confighome == "" {
+		confighome = filepath.Join(homedir, ".config")
+	}
+
+	return filepath.Join(confighome, "lablog", "config.toml")
+}
+
+// DataDirPath returns the path of the shared config file in the datadir.
+func DataDirPath(datadir string) string {
+	return filepath.Join(datadir, DataDirFileName)
+}
+
+// ReadFile parses the config file at the given path. A missing file is not an
+// error and returns no values.
+func ReadFile(path string) (map[string]string, error) {
+	raw, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, errgo.Notef(err, "can not read config file")
+	}
+
+	values, err := Parse(string(raw))
+	if err != nil {
+		return nil, errgo.Notef(err, "can not parse config file "+path)
+	}
+
+	return values, nil
+}
+
+// Env returns the values of all environment variables that override the
+// known keys of the config.
+func (config Config) Env() map[string]string {
+	values := make(map[string]string)
+	for _, key := range config.Keys() {
+		value, ok := os.LookupEnv(EnvName(key))
+		if ok {
+			values[key] = value
+		}
+	}
+
+	return values
+}
+
+// EnvName returns the name of the environment variable for the given key.
+func EnvName(key string) string {
+	name := strings.ToUpper(key)
+	name = strings.Replace(name, ".", "_", -1)
+	name = strings.Replace(name, "-", "_", -1)
+
+	return EnvPrefix + name
+}
+
+// Apply sets all given values in the config. The values are applied in the
+// order of their keys.
+func (config *Config) Apply(values map[string]string) error {
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		err := config.Set(key, values[key])
+		if err != nil {
+			return errgo.Notef(err, "can not set value for "+key)
+		}
+	}
+
+	return nil
+}
+
+// Set sets the value for the given key.
+func (config *Config) Set(key, value string) error {
+	var err error
+
+	switch key {
+	case "datadir":
+		config.DataDir = value
+	case "loglevel":
+		config.LogLevel = value
+	case "autocommit":
+		config.AutoCommit, err = strconv.ParseBool(value)
+	case "web.binding":
+		config.WebBinding = value
+	case "web.renderer":
+		config.WebRenderer = value
+	case "web.commitdelay":
+		config.WebCommitDelay, err = time.ParseDuration(value)
+	case "show.archive":
+		config.ShowArchive, err = strconv.ParseBool(value)
+	case "show.since":
+		config.ShowSince = value
+	case "show.until":
+		config.ShowUntil = value
+	case "show.format":
+		config.ShowFormat = value
+	case "show.output":
+		config.ShowOutput = value
+	case "format.timestamp":
+		config.TimeFormat = value
+	case "vcs.backend":
+		config.VCSBackend = value
+	default:
+		if !strings.HasPrefix(key, "asciidoc.") {
+			return errgo.New("unknown config key " + key)
+		}
+
+		config.setAsciidoc(strings.TrimPrefix(key, "asciidoc."), value)
+	}
+
+	if err != nil {
+		return errgo.Notef(err, "can not parse value")
+	}
+
+	return nil
+}
+
+func (config *Config) setAsciidoc(name, value string) {
+	for i, attribute := range config.Asciidoc {
+		if attribute[0] == name {
+			config.Asciidoc[i][1] = value
+			return
+		}
+	}
+
+	config.Asciidoc = append(config.Asciidoc, [2]string{name, value})
+}
+
+// Keys returns all keys the config currently knows about in the order they
+// are written.
+func (config Config) Keys() []string {
+	keys := []string{
+		"datadir",
+		"loglevel",
+		"autocommit",
+		"web.binding",
+		"web.renderer",
+		"web.commitdelay",
+		"show.archive",
+		"show.since",
+		"show.until",
+		"show.format",
+		"show.output",
+		"format.timestamp",
+		"vcs.backend",
+	}
+
+	for _, attribute := range config.Asciidoc {
+		keys = append(keys, "asciidoc."+attribute[0])
+	}
+
+	return keys
+}
+
+// Get returns the value for the given key as it would be written to a config
+// file.
+func (config Config) Get(key string) string {
+	switch key {
+	case "datadir":
+		return config.DataDir
+	case "loglevel":
+		return config.LogLevel
+	case "autocommit":
+		return strconv.FormatBool(config.AutoCommit)
+	case "web.binding":
+		return config.WebBinding
+	case "web.renderer":
+		return config.WebRenderer
+	case "web.commitdelay":
+		return config.WebCommitDelay.String()
+	case "show.archive":
+		return strconv.FormatBool(config.ShowArchive)
+	case "show.since":
+		return config.ShowSince
+	case "show.until":
+		return config.ShowUntil
+	case "show.format":
+		return config.ShowFormat
+	case "show.output":
+		return config.ShowOutput
+	case "format.timestamp":
+		return config.TimeFormat
+	case "vcs.backend":
+		return config.VCSBackend
+	}
+
+	name := strings.TrimPrefix(key, "asciidoc.")
+	for _, attribute := range config.Asciidoc {
+		if attribute[0] == name {
+			return attribute[1]
+		}
+	}
+
+	return ""
+}
+
+// Write writes the config in the format of a config file.
+func (config Config) Write(writer io.Writer) error {
+	return config.write(writer, config.Keys())
+}
+
+// WriteShared writes the config like Write without the datadir which is not
+// read from the config file in the datadir.
+func (config Config) WriteShared(writer io.Writer) error {
+	var keys []string
+	for _, key := range config.Keys() {
+		if key != "datadir" {
+			keys = append(keys, key)
+		}
+	}
+
+	return config.write(writer, keys)
+}
+
+func (config Config) write(writer io.Writer, keys []string) error {
+	var section string
+	for _, key := range keys {
+		keysection, name := splitKey(key)
+		if keysection != section {
+			_, err := io.WriteString(writer, "\n["+keysection+"]\n")
+			if err != nil {
+				return errgo.Notef(err, "can not write section")
+			}
+
+			section = keysection
+		}
+
+		value := config.Get(key)
+		if key != "autocommit" && key != "show.archive" {
+			value = strconv.Quote(value)
+		}
+
+		_, err := io.WriteString(writer, name+" = "+value+"\n")
+		if err != nil {
+			return errgo.Notef(err, "can not write value")
+		}
+	}
+
+	return nil
+}
+
+func splitKey(key string) (string, string) {
+	index := strings.Index(key, ".")
+	if index == -1 {
+		return "", key
+	}
+
+	return key[:index], key[index+1:]
+}
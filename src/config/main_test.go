@@ -0,0 +1,136 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func Test_Parse(t *testing.T) {
+	raw := `# lablog config
+datadir = "/tmp/lablog" # comment
+autocommit = false
+
+[web]
+binding = ":8080"
+
+[asciidoc]
+toc = "left"
+stem = ""
+quoted = "with \"quotes\" # and hash"
+`
+
+	expected := map[string]string{
+		"datadir":         "/tmp/lablog",
+		"autocommit":      "false",
+		"web.binding":     ":8080",
+		"asciidoc.toc":    "left",
+		"asciidoc.stem":   "",
+		"asciidoc.quoted": `with "quotes" # and hash`,
+	}
+
+	got, err := Parse(raw)
+	testhelper.CompareGotExpected(t, err, got, expected)
+}
+
+func Test_ParseInvalid(t *testing.T) {
+	tests := []string{
+		"[web",
+		"novalue",
+		`key = "open`,
+		`key = "closed" trailing`,
+	}
+
+	for _, raw := range tests {
+		_, err := Parse(raw)
+		if err == nil {
+			t.Fatal("expected error for: ", raw)
+		}
+	}
+}
+
+func Test_Apply(t *testing.T) {
+	config := Default()
+	err := config.Apply(map[string]string{
+		"autocommit":       "false",
+		"show.since":       "7d",
+		"asciidoc.toc":     "left",
+		"asciidoc.stem":    "",
+		"format.timestamp": "2006-01-02",
+		"web.commitdelay":  "1m30s",
+	})
+	if err != nil {
+		t.Fatal("can not apply values: ", err)
+	}
+
+	testhelper.CompareGotExpected(t, nil, config.AutoCommit, false)
+	testhelper.CompareGotExpected(t, nil, config.ShowSince, "7d")
+	testhelper.CompareGotExpected(t, nil, config.TimeFormat, "2006-01-02")
+	testhelper.CompareGotExpected(t, nil, config.WebCommitDelay, 90*time.Second)
+	testhelper.CompareGotExpected(t, nil, config.Get("web.commitdelay"), "1m30s")
+	testhelper.CompareGotExpected(t, nil, config.Asciidoc[0], [2]string{"toc", "left"})
+	testhelper.CompareGotExpected(t, nil, config.Asciidoc[len(config.Asciidoc)-1], [2]string{"stem", ""})
+
+	err = config.Apply(map[string]string{"unknown": "value"})
+	if err == nil {
+		t.Fatal("expected error for unknown key")
+	}
+}
+
+func Test_Env(t *testing.T) {
+	os.Setenv("LABLOG_WEB_BINDING", ":9090")
+	os.Setenv("LABLOG_ASCIIDOC_SOURCE_HIGHLIGHTER", "coderay")
+	defer os.Unsetenv("LABLOG_WEB_BINDING")
+	defer os.Unsetenv("LABLOG_ASCIIDOC_SOURCE_HIGHLIGHTER")
+
+	expected := map[string]string{
+		"web.binding":                 ":9090",
+		"asciidoc.source-highlighter": "coderay",
+	}
+
+	testhelper.CompareGotExpected(t, nil, Default().Env(), expected)
+}
+
+func Test_WriteParse(t *testing.T) {
+	config := Default()
+	config.DataDir = "/tmp/lablog"
+	config.ShowSince = "yesterday"
+
+	buffer := new(bytes.Buffer)
+	err := config.Write(buffer)
+	if err != nil {
+		t.Fatal("can not write config: ", err)
+	}
+
+	values, err := Parse(buffer.String())
+	if err != nil {
+		t.Fatal("can not parse written config: ", err)
+	}
+
+	got := Default()
+	err = got.Apply(values)
+	testhelper.CompareGotExpected(t, err, got, config)
+}
+
+func Test_WriteShared(t *testing.T) {
+	config := Default()
+	config.DataDir = "/tmp/lablog"
+
+	buffer := new(bytes.Buffer)
+	err := config.WriteShared(buffer)
+	if err != nil {
+		t.Fatal("can not write config: ", err)
+	}
+
+	values, err := Parse(buffer.String())
+	if err != nil {
+		t.Fatal("can not parse written config: ", err)
+	}
+
+	if _, ok := values["datadir"]; ok {
+		t.Fatal("expected no datadir in the shared config")
+	}
+}
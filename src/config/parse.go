@@ -0,0 +1,106 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/juju/errgo"
+)
+
+// Parse parses the subset of toml used by the config files. Supported are
+// comments, sections, and keys with quoted strings, booleans or bare values.
+// Keys inside of sections are returned as section.key.
+func Parse(raw string) (map[string]string, error) {
+	values := make(map[string]string)
+
+	var section string
+	for number, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		position := "line " + strconv.Itoa(number+1)
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, errgo.New(position + ": section is not closed")
+			}
+
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if section == "" {
+				return nil, errgo.New(position + ": section name is empty")
+			}
+
+			continue
+		}
+
+		index := strings.Index(line, "=")
+		if index == -1 {
+			return nil, errgo.New(position + ": expected key = value")
+		}
+
+		key := strings.TrimSpace(line[:index])
+		if key == "" {
+			return nil, errgo.New(position + ": key is empty")
+		}
+
+		value, err := parseValue(strings.TrimSpace(line[index+1:]))
+		if err != nil {
+			return nil, errgo.Notef(err, position+": can not parse value")
+		}
+
+		if section != "" {
+			key = section + "." + key
+		}
+
+		values[key] = value
+	}
+
+	return values, nil
+}
+
+func parseValue(raw string) (string, error) {
+	if !strings.HasPrefix(raw, `"`) {
+		// Strip comments after bare values.
+		index := strings.Index(raw, "#")
+		if index != -1 {
+			raw = strings.TrimSpace(raw[:index])
+		}
+
+		return raw, nil
+	}
+
+	end := closingQuote(raw)
+	if end == -1 {
+		return "", errgo.New("quoted value is not closed")
+	}
+	quoted := raw[:end+1]
+
+	rest := strings.TrimSpace(raw[len(quoted):])
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", errgo.New("unexpected text after quoted value")
+	}
+
+	value, err := strconv.Unquote(quoted)
+	if err != nil {
+		return "", errgo.Notef(err, "can not unquote value")
+	}
+
+	return value, nil
+}
+
+// closingQuote returns the index of the quote that closes the quoted value at
+// the start of raw or -1 if there is none.
+func closingQuote(raw string) int {
+	for i := 1; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+
+	return -1
+}
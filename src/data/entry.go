@@ -1,6 +1,8 @@
 package data
 
 import (
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/juju/errgo"
@@ -8,6 +10,44 @@ import (
 
 type Entries []Entry
 
+// MergeEntries combines all given entries into one list sorted by timestamp.
+// Entries with exactly the same values are only kept once. Entries with the
+// same timestamp keep the order in which they were given.
+func MergeEntries(lists ...Entries) Entries {
+	var out Entries
+	seen := make(map[string]struct{})
+
+	for _, entries := range lists {
+		for _, entry := range entries {
+			key := strings.Join(entry.Values(), "\x00")
+			if _, ok := seen[key]; ok {
+				continue
+			}
+
+			seen[key] = struct{}{}
+			out = append(out, entry)
+		}
+	}
+
+	sort.Stable(entriesByTimeStamp(out))
+
+	return out
+}
+
+type entriesByTimeStamp Entries
+
+func (entries entriesByTimeStamp) Len() int {
+	return len(entries)
+}
+
+func (entries entriesByTimeStamp) Less(i, j int) bool {
+	return entries[i].GetTimeStamp().Before(entries[j].GetTimeStamp())
+}
+
+func (entries entriesByTimeStamp) Swap(i, j int) {
+	entries[i], entries[j] = entries[j], entries[i]
+}
+
 type Entry interface {
 	Type() EntryType
 	Values() []string
@@ -0,0 +1,20 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_MergeEntries(t *testing.T) {
+	first := Note{Value: "first", TimeStamp: time.Date(2010, time.November, 10, 23, 0, 0, 0, time.UTC)}
+	second := Todo{Value: "second", TimeStamp: time.Date(2011, time.November, 10, 23, 0, 0, 0, time.UTC), Active: true}
+	third := Note{Value: "third", TimeStamp: time.Date(2012, time.November, 10, 23, 0, 0, 0, time.UTC)}
+
+	got := MergeEntries(Entries{third, first}, Entries{second, first})
+	expected := Entries{first, second, third}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("got %v, expected %v", got, expected)
+	}
+}
@@ -0,0 +1,130 @@
+package data
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+// jsonEntry is the encoding of all entry types in JSON. The type field is
+// always set so lists of mixed entries can be decoded again. Timestamps use
+// TimeStampFormat like the stored entries.
+type jsonEntry struct {
+	Type      string `json:"type"`
+	TimeStamp string `json:"timestamp"`
+	Value     string `json:"value"`
+	Active    *bool  `json:"active,omitempty"`
+	ID        string `json:"id,omitempty"`
+}
+
+func (note Note) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonEntry{
+		Type:      note.Type().String(),
+		TimeStamp: note.TimeStamp.Format(TimeStampFormat),
+		Value:     note.Value,
+	})
+}
+
+func (note *Note) UnmarshalJSON(raw []byte) error {
+	entry, err := ParseEntryJSON(raw)
+	if err != nil {
+		return err
+	}
+
+	parsed, ok := entry.(Note)
+	if !ok {
+		return errgo.New("tried to parse a note but got the entry type " + entry.Type().String())
+	}
+
+	*note = parsed
+
+	return nil
+}
+
+func (todo Todo) MarshalJSON() ([]byte, error) {
+	active := todo.Active
+
+	return json.Marshal(jsonEntry{
+		Type:      todo.Type().String(),
+		TimeStamp: todo.TimeStamp.Format(TimeStampFormat),
+		Value:     todo.Value,
+		Active:    &active,
+		ID:        todo.ID,
+	})
+}
+
+func (todo *Todo) UnmarshalJSON(raw []byte) error {
+	entry, err := ParseEntryJSON(raw)
+	if err != nil {
+		return err
+	}
+
+	parsed, ok := entry.(Todo)
+	if !ok {
+		return errgo.New("tried to parse a todo but got the entry type " + entry.Type().String())
+	}
+
+	*todo = parsed
+
+	return nil
+}
+
+// ParseEntryJSON decodes an entry of any type from its JSON encoding. The
+// timestamp can be omitted and will then be zero. A todo without an active
+// field is active.
+func ParseEntryJSON(raw []byte) (Entry, error) {
+	var values jsonEntry
+	err := json.Unmarshal(raw, &values)
+	if err != nil {
+		return nil, errgo.Notef(err, "can not decode json")
+	}
+
+	etype, err := ParseEntryType(values.Type)
+	if err != nil {
+		return nil, errgo.Notef(err, "can not parse entry type")
+	}
+
+	var timestamp time.Time
+	if values.TimeStamp != "" {
+		timestamp, err = time.Parse(TimeStampFormat, values.TimeStamp)
+		if err != nil {
+			return nil, errgo.Notef(err, "can not parse timestamp")
+		}
+	}
+
+	switch etype {
+	case EntryTypeNote:
+		return Note{TimeStamp: timestamp, Value: values.Value}, nil
+	case EntryTypeTodo:
+		active := true
+		if values.Active != nil {
+			active = *values.Active
+		}
+
+		return Todo{Active: active, TimeStamp: timestamp, Value: values.Value, ID: values.ID}, nil
+	default:
+		return nil, errgo.New("do not know how to parse this entry type")
+	}
+}
+
+func (name ProjectName) MarshalJSON() ([]byte, error) {
+	return json.Marshal(name.String())
+}
+
+func (name *ProjectName) UnmarshalJSON(raw []byte) error {
+	var value string
+	err := json.Unmarshal(raw, &value)
+	if err != nil {
+		return errgo.Notef(err, "can not decode json")
+	}
+
+	parsed, err := ParseProjectName(value)
+	if err != nil {
+		return errgo.Notef(err, "can not parse project name")
+	}
+
+	*name = parsed
+
+	return nil
+}
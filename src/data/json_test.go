@@ -0,0 +1,70 @@
+package data
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_EntryJSON(t *testing.T) {
+	timestamp := time.Date(2010, time.November, 10, 23, 0, 0, 0, time.UTC)
+	entries := Entries{
+		Note{Value: "note", TimeStamp: timestamp},
+		Todo{Value: "todo", TimeStamp: timestamp, ID: "abcdef12"},
+	}
+
+	raw, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[{"type":"note","timestamp":"2010-11-10T23:00:00Z","value":"note"},` +
+		`{"type":"todo","timestamp":"2010-11-10T23:00:00Z","value":"todo","active":false,"id":"abcdef12"}]`
+	if string(raw) != expected {
+		t.Fatalf("got %s, expected %s", raw, expected)
+	}
+
+	var values []json.RawMessage
+	err = json.Unmarshal(raw, &values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got Entries
+	for _, value := range values {
+		entry, err := ParseEntryJSON(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got = append(got, entry)
+	}
+
+	if !reflect.DeepEqual(got, entries) {
+		t.Fatalf("got %v, expected %v", got, entries)
+	}
+}
+
+func Test_ParseEntryJSONDefaults(t *testing.T) {
+	entry, err := ParseEntryJSON([]byte(`{"type":"todo","value":"todo"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Todo{Active: true, Value: "todo"}
+	if !reflect.DeepEqual(entry, expected) {
+		t.Fatalf("got %v, expected %v", entry, expected)
+	}
+
+	_, err = ParseEntryJSON([]byte(`{"type":"unkown","value":"todo"}`))
+	if err == nil {
+		t.Fatal("expected an error for an unkown type")
+	}
+
+	var note Note
+	err = json.Unmarshal([]byte(`{"type":"todo","value":"todo"}`), &note)
+	if err == nil {
+		t.Fatal("expected an error when decoding a todo as a note")
+	}
+}
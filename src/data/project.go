@@ -1,10 +1,13 @@
 package data
 
 import (
+	"sort"
 	"strings"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/armon/go-radix"
+	"github.com/juju/errgo"
 )
 
 type Projects struct {
@@ -62,11 +65,60 @@ func (project Project) Notes() []Note {
 	return out
 }
 
+// Between returns a copy of the project which only contains the entries that
+// were recorded at or after since and before until. A zero time is treated as
+// an open end of the range.
+func (project Project) Between(since, until time.Time) Project {
+	out := Project{Name: project.Name}
+	for _, entry := range project.Entries {
+		timestamp := entry.GetTimeStamp()
+
+		if !since.IsZero() && timestamp.Before(since) {
+			continue
+		}
+
+		if !until.IsZero() && !timestamp.Before(until) {
+			continue
+		}
+
+		out.Entries = append(out.Entries, entry)
+	}
+
+	return out
+}
+
 func (project *Project) AddNote(note Note) {
 	project.Entries = append(project.Entries, note)
 }
 
+// Todos returns the current state of every todo in the project. All records
+// of a todo are folded in the order of their timestamps so the latest record
+// determines the state. The todos are returned in the order they were created.
 func (project Project) Todos() []Todo {
+	history := project.TodoHistory()
+	sort.Stable(todosByTimeStamp(history))
+
+	var order []string
+	current := make(map[string]Todo)
+	for _, todo := range history {
+		key := todo.Key()
+		if _, ok := current[key]; !ok {
+			order = append(order, key)
+		}
+
+		current[key] = todo
+	}
+
+	var out []Todo
+	for _, key := range order {
+		out = append(out, current[key])
+	}
+
+	return out
+}
+
+// TodoHistory returns all todo records of the project without folding them.
+func (project Project) TodoHistory() []Todo {
 	var out []Todo
 	for _, entry := range project.Entries {
 		if entry.Type() == EntryTypeTodo {
@@ -77,6 +129,62 @@ func (project Project) Todos() []Todo {
 	return out
 }
 
+// Todo returns the current state of the todo with the given id. Todos that
+// were recorded before ids existed are found by the id from TodoID and are
+// returned without id so new records are folded together with them.
+func (project Project) Todo(id string) (Todo, bool) {
+	legacy := project.legacyTodoIDs()
+
+	for _, todo := range project.Todos() {
+		if todo.ID == id || (todo.ID == "" && legacy[todo.Value] == id) {
+			return todo, true
+		}
+	}
+
+	return Todo{}, false
+}
+
+// TodoID returns the id of the todo. Todos that were recorded before ids
+// existed get the id that would have been generated for their first record.
+func (project Project) TodoID(todo Todo) string {
+	if todo.ID != "" {
+		return todo.ID
+	}
+
+	return project.legacyTodoIDs()[todo.Value]
+}
+
+// legacyTodoIDs returns the ids of the todos without id by their value.
+func (project Project) legacyTodoIDs() map[string]string {
+	history := project.TodoHistory()
+	sort.Stable(todosByTimeStamp(history))
+
+	ids := make(map[string]string)
+	for _, todo := range history {
+		if _, ok := ids[todo.Value]; ok || todo.ID != "" {
+			continue
+		}
+
+		ids[todo.Value] = NewTodoID(todo.TimeStamp, todo.Value)
+	}
+
+	return ids
+}
+
+type todosByTimeStamp []Todo
+
+func (todos todosByTimeStamp) Len() int {
+	return len(todos)
+}
+
+func (todos todosByTimeStamp) Less(i, j int) bool {
+	return todos[i].TimeStamp.Before(todos[j].TimeStamp)
+}
+
+func (todos todosByTimeStamp) Swap(i, j int) {
+	todos[i], todos[j] = todos[j], todos[i]
+}
+
 func (project *Project) AddTodo(todo Todo) {
 	project.Entries = append(project.Entries, todo)
 }
@@ -85,6 +193,9 @@ type ProjectName []string
 
 const ProjectNameSepperator = "."
 
+// ArchiveName is the first component of the names of archived projects.
+const ArchiveName = ".archive"
+
 func ParseProjectName(name string) (ProjectName, error) {
 	splitted := strings.Split(name, ProjectNameSepperator)
 
@@ -99,6 +210,72 @@ func (name ProjectName) String() string {
 	return strings.Join(name, ProjectNameSepperator)
 }
 
+// HasPrefix returns true if the name starts with all components of the given
+// prefix. Unlike a string prefix "work" is not a prefix of "workshop".
+func (name ProjectName) HasPrefix(prefix ProjectName) bool {
+	if len(prefix) > len(name) {
+		return false
+	}
+
+	for i := range prefix {
+		if name[i] != prefix[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Validate returns an error if the name can not be used for a new entry. Every
+// component is used as a path in the datadir, so components can not be empty,
+// start with a dot or contain path separators. This also rejects the names of
+// archived projects and of the .git folder.
+func (name ProjectName) Validate() error {
+	if len(name) == 0 {
+		return errgo.New("project name can not be empty")
+	}
+
+	if name.IsArchived() {
+		return errgo.New("project name " + name.String() + " is in the archive")
+	}
+
+	for _, component := range name {
+		switch {
+		case component == "":
+			return errgo.New("project name " + name.String() + " has an empty component")
+		case strings.HasPrefix(component, "."):
+			return errgo.New("component " + component + " of project name " + name.String() + " starts with a dot")
+		case strings.ContainsAny(component, `/\`):
+			return errgo.New("component " + component + " of project name " + name.String() + " contains a path separator")
+		}
+	}
+
+	return nil
+}
+
+// IsArchived returns true if the name belongs to an archived project.
+func (name ProjectName) IsArchived() bool {
+	return len(name) > 0 && name[0] == ArchiveName
+}
+
+// Archived returns the name the project has in the archive.
+func (name ProjectName) Archived() ProjectName {
+	if name.IsArchived() {
+		return name
+	}
+
+	return append(ProjectName{ArchiveName}, name...)
+}
+
+// Unarchived returns the name the project has outside of the archive.
+func (name ProjectName) Unarchived() ProjectName {
+	if !name.IsArchived() {
+		return name
+	}
+
+	return append(ProjectName{}, name[1:]...)
+}
+
 func ProjectNamesToString(names []ProjectName) []string {
 	var out []string
 
@@ -0,0 +1,78 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_ProjectNameValidate(t *testing.T) {
+	valid := []ProjectName{
+		{"Test"},
+		{"work", "clientA"},
+		{"work", "client-A"},
+	}
+
+	for _, name := range valid {
+		if err := name.Validate(); err != nil {
+			t.Errorf("expected %v to be valid but got %v", name, err)
+		}
+	}
+
+	invalid := []ProjectName{
+		{},
+		{""},
+		{"work", ""},
+		{"."},
+		{".."},
+		{"", "", "", "", ""},
+		{".git", "x"},
+		{ArchiveName, "x"},
+		{"work", ".hidden"},
+		{"a/..", "x"},
+		{`a\b`},
+	}
+
+	for _, name := range invalid {
+		if err := name.Validate(); err == nil {
+			t.Errorf("expected %v to be invalid", name)
+		}
+	}
+}
+
+func Test_ProjectLegacyTodoID(t *testing.T) {
+	first := time.Date(2010, time.November, 10, 23, 0, 0, 0, time.UTC)
+
+	var project Project
+	project.AddTodo(Todo{Active: true, TimeStamp: first.Add(time.Hour), Value: "legacy"})
+	project.AddTodo(Todo{Active: true, TimeStamp: first, Value: "legacy"})
+	project.AddTodo(Todo{Active: true, TimeStamp: first, Value: "new", ID: "id"})
+
+	id := NewTodoID(first, "legacy")
+
+	todos := project.Todos()
+	if len(todos) != 2 {
+		t.Fatalf("expected two todos but got %v", todos)
+	}
+
+	if got := project.TodoID(todos[0]); got != id {
+		t.Fatalf("got %v, expected %v", got, id)
+	}
+	if got := project.TodoID(todos[1]); got != "id" {
+		t.Fatalf("got %v, expected %v", got, "id")
+	}
+
+	todo, found := project.Todo(id)
+	if !found || todo.Value != "legacy" || todo.ID != "" {
+		t.Fatalf("expected the legacy todo without id but got %v, %v", todo, found)
+	}
+
+	// A new record without id closes the legacy todo.
+	todo.Active = false
+	todo.TimeStamp = first.Add(2 * time.Hour)
+	project.AddTodo(todo)
+
+	todo, found = project.Todo(id)
+	if !found || todo.Active {
+		t.Fatalf("expected the legacy todo to be done but got %v, %v", todo, found)
+	}
+}
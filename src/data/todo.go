@@ -1,6 +1,8 @@
 package data
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"strconv"
 	"time"
 
@@ -11,6 +13,28 @@ type Todo struct {
 	Active    bool
 	TimeStamp time.Time
 	Value     string
+	ID        string
+}
+
+// TodoIDLength is the number of hex characters used for generated todo ids.
+const TodoIDLength = 8
+
+// NewTodoID generates a short identifier for a todo created at the given
+// timestamp with the given value. The id stays the same for all later records
+// that change the state of that todo.
+func NewTodoID(timestamp time.Time, value string) string {
+	hash := sha1.Sum([]byte(timestamp.Format(TimeStampFormat) + value))
+	return hex.EncodeToString(hash[:])[:TodoIDLength]
+}
+
+// Key returns the identifier used to group the records of a todo. Todos that
+// were recorded before ids existed are grouped by their value.
+func (todo Todo) Key() string {
+	if todo.ID == "" {
+		return todo.Value
+	}
+
+	return todo.ID
 }
 
 func (todo Todo) Type() EntryType {
@@ -18,12 +42,18 @@ func (todo Todo) Type() EntryType {
 }
 
 func (todo Todo) Values() []string {
-	return []string{
+	values := []string{
 		todo.Type().String(),
 		todo.TimeStamp.Format(TimeStampFormat),
 		strconv.FormatBool(todo.Active),
 		todo.Value,
 	}
+
+	if todo.ID != "" {
+		values = append(values, todo.ID)
+	}
+
+	return values
 }
 
 func (todo Todo) GetTimeStamp() time.Time {
@@ -31,8 +61,8 @@ func (todo Todo) GetTimeStamp() time.Time {
 }
 
 func ParseTodo(values []string) (Todo, error) {
-	if len(values) != 4 {
-		return Todo{}, errgo.New("entry with the type todo needs exactly four fields")
+	if len(values) != 4 && len(values) != 5 {
+		return Todo{}, errgo.New("entry with the type todo needs four or five fields")
 	}
 
 	etype, err := ParseEntryType(values[0])
@@ -53,5 +83,10 @@ func ParseTodo(values []string) (Todo, error) {
 		return Todo{}, errgo.Notef(err, "can not parse active state")
 	}
 
-	return Todo{Active: active, TimeStamp: timestamp, Value: values[3]}, nil
+	todo := Todo{Active: active, TimeStamp: timestamp, Value: values[3]}
+	if len(values) == 5 {
+		todo.ID = values[4]
+	}
+
+	return todo, nil
 }
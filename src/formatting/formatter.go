@@ -0,0 +1,163 @@
+package formatting
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/juju/errgo"
+)
+
+// Formatter writes the parts of a document in one markup language. The
+// Format functions use it to write projects and their entries.
+type Formatter interface {
+	// Settings writes the settings in front of the document.
+	Settings(writer io.Writer)
+	// Title writes the title of the document.
+	Title(writer io.Writer, indent int, title string)
+	// Header writes a header with the depth of the indent.
+	Header(writer io.Writer, indent int, title string)
+	// Todos writes the todos as a list.
+	Todos(writer io.Writer, todos []data.Todo)
+	// NotesValue writes the value of a note and moves the headers in it below
+	// the given indent.
+	NotesValue(writer io.Writer, value string, indent int)
+}
+
+// DefaultFormat is the name of the format used if none is given.
+const DefaultFormat = "asciidoc"
+
+// Formatters contains all formatters by the name of their format.
+var Formatters = map[string]Formatter{
+	DefaultFormat: Asciidoc{},
+	"markdown":    Markdown{},
+}
+
+// ParseFormat returns the formatter for the format with the given name.
+func ParseFormat(name string) (Formatter, error) {
+	formatter, ok := Formatters[name]
+	if !ok {
+		var names []string
+		for name := range Formatters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return nil, errgo.New("unknown format " + name + ", possible formats are " +
+			strings.Join(names, ", "))
+	}
+
+	return formatter, nil
+}
+
+// Asciidoc is the formatter for asciidoc documents.
+type Asciidoc struct{}
+
+func (Asciidoc) Settings(writer io.Writer) {
+	HeaderSettings(writer)
+}
+
+func (Asciidoc) Title(writer io.Writer, indent int, title string) {
+	io.WriteString(writer, HeaderIndent(indent)+" "+title+"\n\n")
+}
+
+func (Asciidoc) Header(writer io.Writer, indent int, title string) {
+	io.WriteString(writer, HeaderIndent(indent)+" "+title+"\n")
+}
+
+func (Asciidoc) Todos(writer io.Writer, todos []data.Todo) {
+	Todos(writer, todos)
+}
+
+func (Asciidoc) NotesValue(writer io.Writer, value string, indent int) {
+	NotesValue(writer, value, indent)
+}
+
+func FormatProjects(writer io.Writer, formatter Formatter, command string, indent int, projects *data.Projects) {
+	formatter.Settings(writer)
+	formatter.Title(writer, indent+1, command)
+	for _, project := range projects.List() {
+		FormatProject(writer, formatter, indent+1, &project)
+	}
+}
+
+func FormatProjectsNotes(writer io.Writer, formatter Formatter, command string, indent int, projects *data.Projects) {
+	formatter.Settings(writer)
+	formatter.Title(writer, indent+1, command)
+	for _, project := range projects.List() {
+		FormatProjectNotes(writer, formatter, indent+1, &project)
+	}
+}
+
+func FormatProjectsTodos(writer io.Writer, formatter Formatter, command string, indent int, projects *data.Projects) {
+	formatter.Settings(writer)
+	formatter.Title(writer, indent+1, command)
+	for _, project := range projects.List() {
+		FormatProjectTodos(writer, formatter, indent+1, &project)
+	}
+}
+
+func FormatProject(writer io.Writer, formatter Formatter, indent int, project *data.Project) {
+	todos := project.Todos()
+	notes := project.Notes()
+
+	if len(todos) == 0 && len(notes) == 0 {
+		return
+	}
+
+	formatter.Header(writer, indent+1, project.Name.String())
+
+	if len(todos) != 0 {
+		formatter.Header(writer, indent+2, "Todos")
+		formatter.Todos(writer, todos)
+	}
+
+	if len(notes) != 0 {
+		formatter.Header(writer, indent+2, "Notes")
+		FormatNotes(writer, formatter, indent+3, notes)
+	}
+}
+
+func FormatProjectNotes(writer io.Writer, formatter Formatter, indent int, project *data.Project) {
+	if len(project.Notes()) == 0 {
+		return
+	}
+
+	formatter.Header(writer, indent+1, project.Name.String())
+	FormatNotes(writer, formatter, indent+2, project.Notes())
+}
+
+// FormatProjectTodos writes the todos of the project. Projects without active
+// todos are skipped so done todos do not leave an empty header.
+func FormatProjectTodos(writer io.Writer, formatter Formatter, indent int, project *data.Project) {
+	todos := project.Todos()
+	if !hasActiveTodos(todos) {
+		return
+	}
+
+	formatter.Header(writer, indent+1, project.Name.String())
+	formatter.Todos(writer, todos)
+}
+
+func hasActiveTodos(todos []data.Todo) bool {
+	for _, todo := range todos {
+		if todo.Active {
+			return true
+		}
+	}
+
+	return false
+}
+
+func FormatNotes(writer io.Writer, formatter Formatter, indent int, notes []data.Note) {
+	for _, note := range notes {
+		if note.Value == "" {
+			continue
+		}
+
+		formatter.Header(writer, indent, note.TimeStamp.Format(HeaderTimeFormat))
+		formatter.NotesValue(writer, note.Value, indent+1)
+		io.WriteString(writer, "\n")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"io"
 	"strings"
 
+	"github.com/AlexanderThaller/lablog/src/config"
 	"github.com/AlexanderThaller/lablog/src/data"
 	log "github.com/Sirupsen/logrus"
 )
@@ -19,21 +20,24 @@ func HeaderIndent(indent int) string {
 	return out
 }
 
+// HeaderAttributes are the asciidoc document attributes written by
+// HeaderSettings. Attributes with the value "!" are written unset.
+var HeaderAttributes = config.Default().Asciidoc
+
 func HeaderSettings(writer io.Writer) {
-	settings := `:toc: right
-:toclevels: 4
-:sectanchors:
-:sectlink:
-:icons: font
-:linkattrs:
-:numbered:
-:idprefix:
-:idseparator: -
-:doctype: book
-:source-highlighter: pygments
-:listing-caption: Listing`
-
-	io.WriteString(writer, settings+"\n\n")
+	var settings []string
+	for _, attribute := range HeaderAttributes {
+		switch attribute[1] {
+		case "":
+			settings = append(settings, ":"+attribute[0]+":")
+		case "!":
+			settings = append(settings, ":"+attribute[0]+"!:")
+		default:
+			settings = append(settings, ":"+attribute[0]+": "+attribute[1])
+		}
+	}
+
+	io.WriteString(writer, strings.Join(settings, "\n")+"\n\n")
 }
 
 func HeaderProjects(writer io.Writer, command string, indent int, project *data.Projects) {
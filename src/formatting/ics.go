@@ -0,0 +1,124 @@
+package formatting
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+)
+
+const (
+	icsTimeFormat = "20060102T150405Z"
+	// icsLineLength is the maximum length of a line in octets before it has to
+	// be folded.
+	icsLineLength = 75
+)
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// ICS writes the notes of the projects as VJOURNAL and the current state of
+// the todos as VTODO components of an iCalendar. DTSTAMP is the timestamp of
+// the entry and the project name is used as category.
+func ICS(writer io.Writer, projects *data.Projects) error {
+	buffer := new(bytes.Buffer)
+
+	icsLine(buffer, "BEGIN", "VCALENDAR")
+	icsLine(buffer, "VERSION", "2.0")
+	icsLine(buffer, "PRODID", "-//lablog//lablog//EN")
+
+	for _, project := range projects.List() {
+		name := project.Name.String()
+
+		for _, note := range project.Notes() {
+			icsLine(buffer, "BEGIN", "VJOURNAL")
+			icsLine(buffer, "UID", icsUID(name, note))
+			icsLine(buffer, "DTSTAMP", note.TimeStamp.UTC().Format(icsTimeFormat))
+			icsLine(buffer, "DTSTART", note.TimeStamp.UTC().Format(icsTimeFormat))
+			icsLine(buffer, "SUMMARY", icsEscaper.Replace(summary(note.Value)))
+			icsLine(buffer, "DESCRIPTION", icsEscaper.Replace(note.Value))
+			icsLine(buffer, "CATEGORIES", icsEscaper.Replace(name))
+			icsLine(buffer, "END", "VJOURNAL")
+		}
+
+		created := todosCreated(project)
+		for _, todo := range project.Todos() {
+			uid := todo.ID + "@lablog"
+			if todo.ID == "" {
+				uid = icsUID(name, data.Todo{TimeStamp: created[todo.Key()], Value: todo.Value})
+			}
+
+			icsLine(buffer, "BEGIN", "VTODO")
+			icsLine(buffer, "UID", uid)
+			icsLine(buffer, "DTSTAMP", todo.TimeStamp.UTC().Format(icsTimeFormat))
+			icsLine(buffer, "CREATED", created[todo.Key()].UTC().Format(icsTimeFormat))
+			icsLine(buffer, "SUMMARY", icsEscaper.Replace(todo.Value))
+			icsLine(buffer, "CATEGORIES", icsEscaper.Replace(name))
+			if todo.Active {
+				icsLine(buffer, "STATUS", "NEEDS-ACTION")
+			} else {
+				icsLine(buffer, "STATUS", "COMPLETED")
+				icsLine(buffer, "COMPLETED", todo.TimeStamp.UTC().Format(icsTimeFormat))
+			}
+			icsLine(buffer, "END", "VTODO")
+		}
+	}
+
+	icsLine(buffer, "END", "VCALENDAR")
+
+	_, err := buffer.WriteTo(writer)
+	return err
+}
+
+// icsLine writes the property and folds it into lines of at most
+// icsLineLength octets without splitting utf-8 characters.
+func icsLine(buffer *bytes.Buffer, name, value string) {
+	line := name + ":" + value
+
+	limit := icsLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8Start(line[cut]) {
+			cut--
+		}
+
+		buffer.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+
+		// The leading space of the continuation line counts to its length.
+		limit = icsLineLength - 1
+	}
+
+	buffer.WriteString(line + "\r\n")
+}
+
+func utf8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+func icsUID(project string, entry data.Entry) string {
+	hash := sha1.Sum([]byte(project + "\x00" + strings.Join(entry.Values(), "\x00")))
+	return hex.EncodeToString(hash[:]) + "@lablog"
+}
+
+// summary returns the first line of the value.
+func summary(value string) string {
+	return strings.TrimSpace(strings.SplitN(value, "\n", 2)[0])
+}
+
+// todosCreated returns the timestamp of the first record of every todo in the
+// project by the key of the todo.
+func todosCreated(project data.Project) map[string]time.Time {
+	created := make(map[string]time.Time)
+	for _, todo := range project.TodoHistory() {
+		key := todo.Key()
+		if current, ok := created[key]; !ok || todo.TimeStamp.Before(current) {
+			created[key] = todo.TimeStamp
+		}
+	}
+
+	return created
+}
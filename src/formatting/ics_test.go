@@ -0,0 +1,58 @@
+package formatting
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func Test_ICS(t *testing.T) {
+	project := testhelper.GetTestProject("A", 1, 1)
+
+	done := testhelper.GetTestTodo(1, "todo todo todo")
+	done.ID = project.Todos()[0].ID
+	done.Active = false
+	project.AddTodo(done)
+
+	projects := data.NewProjects()
+	projects.Add(project)
+
+	expected := strings.Replace(`BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//lablog//lablog//EN
+BEGIN:VJOURNAL
+UID:`+icsUID("Test.Project.A", project.Notes()[0])+`
+DTSTAMP:20101110T230000Z
+DTSTART:20101110T230000Z
+SUMMARY:note note note
+DESCRIPTION:note note note
+CATEGORIES:Test.Project.A
+END:VJOURNAL
+BEGIN:VTODO
+UID:`+done.ID+`@lablog
+DTSTAMP:20111110T230000Z
+CREATED:20101110T230000Z
+SUMMARY:todo todo todo
+CATEGORIES:Test.Project.A
+STATUS:COMPLETED
+COMPLETED:20111110T230000Z
+END:VTODO
+END:VCALENDAR
+`, "\n", "\r\n", -1)
+
+	got := new(bytes.Buffer)
+	err := ICS(got, &projects)
+	testhelper.CompareGotExpected(t, err, got.String(), expected)
+}
+
+func Test_ICSLine(t *testing.T) {
+	expected := "DESCRIPTION:" + strings.Repeat("a", 63) + "\r\n " +
+		strings.Repeat("a", 73) + "\r\n " + `äb\, c\;\n` + "\r\n"
+
+	got := new(bytes.Buffer)
+	icsLine(got, "DESCRIPTION", icsEscaper.Replace(strings.Repeat("a", 136)+"äb, c;\n"))
+	testhelper.CompareGotExpected(t, nil, got.String(), expected)
+}
@@ -0,0 +1,34 @@
+package formatting
+
+import (
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/juju/errgo"
+)
+
+// FindEntries will return the entries of the project that are referenced by
+// the given key. The key can either be the timestamp of an entry in the
+// format it is saved in or in the format it is shown in, or the id of a todo
+// in which case all records of that todo are returned. Todos without id are
+// referenced by the id from data.Project.TodoID.
+func FindEntries(project data.Project, key string) ([]data.Entry, error) {
+	var out []data.Entry
+	for _, entry := range project.Entries {
+		timestamp := entry.GetTimeStamp()
+
+		switch {
+		case timestamp.Format(data.TimeStampFormat) == key:
+		case timestamp.Format(HeaderTimeFormat) == key:
+		case entry.Type() == data.EntryTypeTodo && project.TodoID(entry.(data.Todo)) == key:
+		default:
+			continue
+		}
+
+		out = append(out, entry)
+	}
+
+	if len(out) == 0 {
+		return nil, errgo.New("no entry found for " + key + " in project " + project.Name.String())
+	}
+
+	return out, nil
+}
@@ -0,0 +1,34 @@
+package formatting
+
+import (
+	"testing"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func Test_FindEntries(t *testing.T) {
+	note := testhelper.GetTestNote(0, "note")
+	todo := testhelper.GetTestTodo(1, "todo")
+	done := todo
+	done.Active = false
+	done.TimeStamp = done.TimeStamp.AddDate(1, 0, 0)
+
+	project := data.Project{Name: data.ProjectName{"Test"}, Entries: data.Entries{note, todo, done}}
+
+	tests := map[string][]data.Entry{
+		note.TimeStamp.Format(data.TimeStampFormat): {note},
+		note.TimeStamp.Format(HeaderTimeFormat):     {note},
+		todo.ID:                                     {todo, done},
+	}
+
+	for key, expected := range tests {
+		got, err := FindEntries(project, key)
+		testhelper.CompareGotExpected(t, err, got, expected)
+	}
+
+	_, err := FindEntries(project, "missing")
+	if err == nil {
+		t.Fatal("expected an error for a key without entries")
+	}
+}
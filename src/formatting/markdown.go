@@ -0,0 +1,90 @@
+package formatting
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+)
+
+// MarkdownMaxDepth is the deepest header Markdown supports. Deeper headers are
+// written with this depth.
+const MarkdownMaxDepth = 6
+
+// markdownHeading matches Markdown headings and the asciidoc headings notes
+// were written with before Markdown was supported.
+var markdownHeading = regexp.MustCompile(`^(#+|=+)\s+(.*)$`)
+
+// Markdown is the formatter for Markdown documents.
+type Markdown struct{}
+
+func MarkdownIndent(indent int) string {
+	if indent < 1 {
+		return ""
+	}
+
+	if indent > MarkdownMaxDepth {
+		indent = MarkdownMaxDepth
+	}
+
+	return strings.Repeat("#", indent)
+}
+
+// Settings writes nothing as Markdown has no document attributes.
+func (Markdown) Settings(writer io.Writer) {}
+
+func (Markdown) Title(writer io.Writer, indent int, title string) {
+	io.WriteString(writer, MarkdownIndent(indent)+" "+title+"\n\n")
+}
+
+func (Markdown) Header(writer io.Writer, indent int, title string) {
+	io.WriteString(writer, MarkdownIndent(indent)+" "+title+"\n\n")
+}
+
+// Todos writes all todos as a task list with the done todos checked.
+func (Markdown) Todos(writer io.Writer, todos []data.Todo) {
+	if len(todos) == 0 {
+		return
+	}
+
+	for _, todo := range todos {
+		if todo.Active {
+			io.WriteString(writer, "- [ ] "+todo.Value+"\n")
+		} else {
+			io.WriteString(writer, "- [x] "+todo.Value+"\n")
+		}
+	}
+
+	io.WriteString(writer, "\n")
+}
+
+// NotesValue writes the value and moves the headings in it so a heading of
+// depth one gets the depth of the indent. Lines in fenced code blocks are kept
+// as they are.
+func (Markdown) NotesValue(writer io.Writer, value string, indent int) {
+	var fenced bool
+
+	lines := strings.Split(value, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+
+		if fenced {
+			continue
+		}
+
+		match := markdownHeading.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		lines[i] = MarkdownIndent(indent+len(match[1])-1) + " " + match[2]
+	}
+
+	io.WriteString(writer, strings.Join(lines, "\n"))
+	io.WriteString(writer, "\n")
+}
@@ -0,0 +1,79 @@
+package formatting
+
+import (
+	"bytes"
+	"testing"
+
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func Test_MarkdownProject(t *testing.T) {
+	expected := `# Test.Project.A
+
+## Todos
+
+- [ ] todo todo todo
+- [x] todo todo todo
+
+## Notes
+
+### 2010-11-10 23:00:00
+
+note note note` + "\n\n"
+
+	project := testhelper.GetTestProject("A", 1, 0)
+	project.AddTodo(testhelper.GetTestTodo(0, "todo todo todo"))
+
+	done := testhelper.GetTestTodo(1, "todo todo todo")
+	done.Active = false
+	project.AddTodo(done)
+
+	got := new(bytes.Buffer)
+	FormatProject(got, Markdown{}, 0, &project)
+
+	testhelper.CompareGotExpected(t, nil, got.String(), expected)
+}
+
+func Test_MarkdownNotesValue(t *testing.T) {
+	expected := `### 2010-11-10 23:00:00
+
+#### Header In Note Value
+note note note
+
+##### SubHeader In Note Value
+` + "```" + `
+# not a header
+` + "```" + `
+
+#### Asciidoc Header In Note Value
+###### Too Deep` + "\n\n"
+
+	value := `# Header In Note Value
+note note note
+
+## SubHeader In Note Value
+` + "```" + `
+# not a header
+` + "```" + `
+
+= Asciidoc Header In Note Value
+#### Too Deep`
+
+	project := testhelper.GetTestProject("A", 0, 0)
+	project.AddNote(testhelper.GetTestNote(0, value))
+
+	got := new(bytes.Buffer)
+	FormatNotes(got, Markdown{}, 3, project.Notes())
+
+	testhelper.CompareGotExpected(t, nil, got.String(), expected)
+}
+
+func Test_ParseFormat(t *testing.T) {
+	formatter, err := ParseFormat("markdown")
+	testhelper.CompareGotExpected(t, err, formatter, Markdown{})
+
+	_, err = ParseFormat("unkown")
+	if err == nil {
+		t.Fatal("expected an error for an unkown format")
+	}
+}
@@ -4,25 +4,16 @@ import (
 	"io"
 	"regexp"
 
+	"github.com/AlexanderThaller/lablog/src/config"
 	"github.com/AlexanderThaller/lablog/src/data"
 )
 
-const (
-	HeaderTimeFormat = "2006-01-02 15:04:05"
-)
+// HeaderTimeFormat is the format used for the timestamps in the headers of
+// notes.
+var HeaderTimeFormat = config.Default().TimeFormat
 
 func Notes(writer io.Writer, indent int, notes []data.Note) {
-	for _, note := range notes {
-		if note.Value == "" {
-			continue
-		}
-
-		io.WriteString(writer, HeaderIndent(indent)+" ")
-		io.WriteString(writer, note.TimeStamp.Format(HeaderTimeFormat)+"\n")
-
-		NotesValue(writer, note.Value, indent+1)
-		io.WriteString(writer, "\n")
-	}
+	FormatNotes(writer, Asciidoc{}, indent, notes)
 }
 
 func NotesValue(writer io.Writer, value string, indent int) {
@@ -35,10 +26,5 @@ func NotesValue(writer io.Writer, value string, indent int) {
 }
 
 func ProjectNotes(writer io.Writer, indent int, project *data.Project) {
-	if len(project.Notes()) == 0 {
-		return
-	}
-
-	HeaderProject(writer, indent+1, project)
-	Notes(writer, indent+2, project.Notes())
+	FormatProjectNotes(writer, Asciidoc{}, indent, project)
 }
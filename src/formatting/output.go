@@ -0,0 +1,170 @@
+package formatting
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/juju/errgo"
+)
+
+// Outputs are the machine readable formats WriteRecords supports.
+var Outputs = []string{"json", "ndjson", "csv"}
+
+// Record is one line of machine readable output.
+type Record interface {
+	CSVValues() []string
+}
+
+// EntryRecord describes one entry of a project. Active is only set for todos.
+type EntryRecord struct {
+	Project   string `json:"project"`
+	Type      string `json:"type"`
+	TimeStamp string `json:"timestamp"`
+	Active    *bool  `json:"active,omitempty"`
+	Value     string `json:"value"`
+	ID        string `json:"id,omitempty"`
+}
+
+// EntryRecordHeader is the csv header for entry records.
+var EntryRecordHeader = []string{"project", "type", "timestamp", "active", "value", "id"}
+
+func (record EntryRecord) CSVValues() []string {
+	var active string
+	if record.Active != nil {
+		active = strconv.FormatBool(*record.Active)
+	}
+
+	return []string{record.Project, record.Type, record.TimeStamp, active, record.Value, record.ID}
+}
+
+// ProjectRecord describes a project.
+type ProjectRecord struct {
+	Project string `json:"project"`
+}
+
+// ProjectRecordHeader is the csv header for project records.
+var ProjectRecordHeader = []string{"project"}
+
+func (record ProjectRecord) CSVValues() []string {
+	return []string{record.Project}
+}
+
+// DateRecord describes a day on which entries were recorded.
+type DateRecord struct {
+	Date string `json:"date"`
+}
+
+// DateRecordHeader is the csv header for date records.
+var DateRecordHeader = []string{"date"}
+
+func (record DateRecord) CSVValues() []string {
+	return []string{record.Date}
+}
+
+// ProjectsRecords returns a record for every note and every todo of the
+// projects. Todos are returned in their current state. The records of a
+// project are sorted by their timestamp and identical entries are only returned
+// once.
+func ProjectsRecords(projects *data.Projects, notes, todos bool) []Record {
+	var records []Record
+
+	for _, project := range projects.List() {
+		var entries data.Entries
+
+		if todos {
+			for _, todo := range project.Todos() {
+				entries = append(entries, todo)
+			}
+		}
+
+		if notes {
+			for _, note := range project.Notes() {
+				entries = append(entries, note)
+			}
+		}
+
+		for _, entry := range data.MergeEntries(entries) {
+			records = append(records, NewEntryRecord(project.Name, entry))
+		}
+	}
+
+	return records
+}
+
+func NewEntryRecord(project data.ProjectName, entry data.Entry) EntryRecord {
+	record := EntryRecord{
+		Project:   project.String(),
+		Type:      entry.Type().String(),
+		TimeStamp: entry.GetTimeStamp().Format(data.TimeStampFormat),
+	}
+
+	switch entry := entry.(type) {
+	case data.Note:
+		record.Value = entry.Value
+	case data.Todo:
+		active := entry.Active
+		record.Active = &active
+		record.Value = entry.Value
+		record.ID = entry.ID
+	}
+
+	return record
+}
+
+// CheckOutput returns an error if the output is not one of Outputs.
+func CheckOutput(output string) error {
+	for _, known := range Outputs {
+		if output == known {
+			return nil
+		}
+	}
+
+	return errgo.New("unknown output " + output + ", possible outputs are json, ndjson and csv")
+}
+
+// WriteRecords writes the records in the given output. The header is only
+// used for csv.
+func WriteRecords(writer io.Writer, output string, header []string, records []Record) error {
+	switch output {
+	case "json":
+		if records == nil {
+			records = []Record{}
+		}
+
+		encoder := json.NewEncoder(writer)
+		encoder.SetEscapeHTML(false)
+
+		err := encoder.Encode(records)
+		if err != nil {
+			return errgo.Notef(err, "can not write json")
+		}
+	case "ndjson":
+		encoder := json.NewEncoder(writer)
+		encoder.SetEscapeHTML(false)
+
+		for _, record := range records {
+			err := encoder.Encode(record)
+			if err != nil {
+				return errgo.Notef(err, "can not write json")
+			}
+		}
+	case "csv":
+		values := [][]string{header}
+		for _, record := range records {
+			values = append(values, record.CSVValues())
+		}
+
+		csvwriter := csv.NewWriter(writer)
+		err := csvwriter.WriteAll(values)
+		if err != nil {
+			return errgo.Notef(err, "can not write csv")
+		}
+	default:
+		return CheckOutput(output)
+	}
+
+	return nil
+}
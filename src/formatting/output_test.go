@@ -0,0 +1,48 @@
+package formatting
+
+import (
+	"bytes"
+	"testing"
+
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func Test_WriteRecords(t *testing.T) {
+	projects := testhelper.GetTestProjects(1, 1, "A")
+	project := testhelper.GetTestProject("B", 0, 0)
+	project.AddNote(testhelper.GetTestNote(1, "multi\nline, \"quoted\""))
+	projects.Add(project)
+
+	records := ProjectsRecords(&projects, true, true)
+
+	tests := map[string]string{
+		"json": `[{"project":"Test.Project.A","type":"todo","timestamp":"2010-11-10T23:00:00Z","active":true,"value":"todo todo todo","id":"` + testhelper.GetTestTodo(0, "todo todo todo").ID + `"},` +
+			`{"project":"Test.Project.A","type":"note","timestamp":"2010-11-10T23:00:00Z","value":"note note note"},` +
+			`{"project":"Test.Project.B","type":"note","timestamp":"2011-11-10T23:00:00Z","value":"multi\nline, \"quoted\""}]` + "\n",
+		"ndjson": `{"project":"Test.Project.A","type":"todo","timestamp":"2010-11-10T23:00:00Z","active":true,"value":"todo todo todo","id":"` + testhelper.GetTestTodo(0, "todo todo todo").ID + `"}` + "\n" +
+			`{"project":"Test.Project.A","type":"note","timestamp":"2010-11-10T23:00:00Z","value":"note note note"}` + "\n" +
+			`{"project":"Test.Project.B","type":"note","timestamp":"2011-11-10T23:00:00Z","value":"multi\nline, \"quoted\""}` + "\n",
+		"csv": "project,type,timestamp,active,value,id\n" +
+			"Test.Project.A,todo,2010-11-10T23:00:00Z,true,todo todo todo," + testhelper.GetTestTodo(0, "todo todo todo").ID + "\n" +
+			"Test.Project.A,note,2010-11-10T23:00:00Z,,note note note,\n" +
+			"Test.Project.B,note,2011-11-10T23:00:00Z,,\"multi\nline, \"\"quoted\"\"\",\n",
+	}
+
+	for output, expected := range tests {
+		got := new(bytes.Buffer)
+		err := WriteRecords(got, output, EntryRecordHeader, records)
+
+		testhelper.CompareGotExpected(t, err, got.String(), expected)
+	}
+}
+
+func Test_WriteRecordsEmpty(t *testing.T) {
+	got := new(bytes.Buffer)
+	err := WriteRecords(got, "json", ProjectRecordHeader, nil)
+	testhelper.CompareGotExpected(t, err, got.String(), "[]\n")
+
+	err = WriteRecords(new(bytes.Buffer), "xml", ProjectRecordHeader, nil)
+	if err == nil {
+		t.Fatal("expected an error for an unkown output")
+	}
+}
@@ -0,0 +1,42 @@
+package formatting
+
+import (
+	"io"
+	"strings"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/search"
+)
+
+// SearchResults writes the results grouped by their project. The query is
+// written as a literal block below the title so markup in it is not rendered.
+func SearchResults(writer io.Writer, query string, indent int, results []search.Result) {
+	HeaderSettings(writer)
+	io.WriteString(writer, HeaderIndent(indent+1)+" Search\n\n")
+	io.WriteString(writer, "....\n"+strings.Join(strings.Fields(query), " ")+"\n....\n\n")
+
+	var current string
+	for _, result := range results {
+		if result.Project.String() != current {
+			current = result.Project.String()
+			HeaderProject(writer, indent+2, &data.Project{Name: result.Project})
+		}
+
+		SearchResult(writer, indent+3, result)
+	}
+}
+
+func SearchResult(writer io.Writer, indent int, result search.Result) {
+	io.WriteString(writer, HeaderIndent(indent)+" ")
+	io.WriteString(writer, result.Entry.GetTimeStamp().Format(HeaderTimeFormat))
+	io.WriteString(writer, " ("+result.Entry.Type().String()+")\n")
+
+	SearchSnippet(writer, result.Snippet)
+	io.WriteString(writer, "\n")
+}
+
+// SearchSnippet writes the snippet and highlights the matched part with the
+// asciidoc mark syntax.
+func SearchSnippet(writer io.Writer, snippet search.Snippet) {
+	io.WriteString(writer, snippet.Before+"##"+snippet.Match+"##"+snippet.After+"\n")
+}
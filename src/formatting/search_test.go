@@ -0,0 +1,27 @@
+package formatting
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/AlexanderThaller/lablog/src/search"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func Test_SearchResult(t *testing.T) {
+	expected := `=== 2010-11-10 23:00:00 (note)
+note ##note## note` + "\n\n"
+
+	got := new(bytes.Buffer)
+
+	project := testhelper.GetTestProject("A", 1, 0)
+	result := search.Result{
+		Project: project.Name,
+		Entry:   project.Notes()[0],
+		Snippet: search.Snippet{Before: "note ", Match: "note", After: " note"},
+	}
+
+	SearchResult(got, 3, result)
+
+	testhelper.CompareGotExpected(t, nil, got.String(), expected)
+}
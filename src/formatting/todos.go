@@ -22,10 +22,5 @@ func Todos(writer io.Writer, todos []data.Todo) {
 }
 
 func ProjectTodos(writer io.Writer, indent int, project *data.Project) {
-	if len(project.Todos()) == 0 {
-		return
-	}
-
-	HeaderProject(writer, indent+1, project)
-	Todos(writer, project.Todos())
+	FormatProjectTodos(writer, Asciidoc{}, indent, project)
 }
@@ -3,6 +3,7 @@ package formatting
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	testhelper "github.com/AlexanderThaller/lablog/src/testing"
 )
@@ -51,3 +52,76 @@ func Test_ProjectTodos(t *testing.T) {
 
 	testhelper.CompareGotExpected(t, nil, got.String(), expected)
 }
+
+func Test_TodosDoneRecord(t *testing.T) {
+	expected := ``
+
+	got := new(bytes.Buffer)
+	project := testhelper.GetTestProject("A", 0, 1)
+
+	done := testhelper.GetTestTodo(0, "todo todo todo")
+	done.Active = false
+	done.TimeStamp = done.TimeStamp.Add(time.Hour)
+	project.AddTodo(done)
+
+	Todos(got, project.Todos())
+
+	testhelper.CompareGotExpected(t, nil, got.String(), expected)
+}
+
+func Test_TodosReopenRecord(t *testing.T) {
+	expected := `* todo todo todo` + "\n\n"
+
+	got := new(bytes.Buffer)
+	project := testhelper.GetTestProject("A", 0, 1)
+
+	done := testhelper.GetTestTodo(0, "todo todo todo")
+	done.Active = false
+	done.TimeStamp = done.TimeStamp.Add(time.Hour)
+	project.AddTodo(done)
+
+	reopen := done
+	reopen.Active = true
+	reopen.TimeStamp = done.TimeStamp.Add(time.Hour)
+	project.AddTodo(reopen)
+
+	Todos(got, project.Todos())
+
+	testhelper.CompareGotExpected(t, nil, got.String(), expected)
+}
+
+func Test_TodosLegacyDoneRecord(t *testing.T) {
+	expected := ``
+
+	got := new(bytes.Buffer)
+	project := testhelper.GetTestProject("A", 0, 0)
+
+	todo := testhelper.GetTestTodo(0, "todo todo todo")
+	todo.ID = ""
+	project.AddTodo(todo)
+
+	done := todo
+	done.Active = false
+	done.TimeStamp = todo.TimeStamp.Add(time.Hour)
+	project.AddTodo(done)
+
+	Todos(got, project.Todos())
+
+	testhelper.CompareGotExpected(t, nil, got.String(), expected)
+}
+
+func Test_ProjectTodosDone(t *testing.T) {
+	expected := ``
+
+	got := new(bytes.Buffer)
+	project := testhelper.GetTestProject("A", 1, 1)
+
+	done := project.Todos()[0]
+	done.Active = false
+	done.TimeStamp = done.TimeStamp.Add(time.Hour)
+	project.AddTodo(done)
+
+	ProjectTodos(got, 0, &project)
+
+	testhelper.CompareGotExpected(t, nil, got.String(), expected)
+}
@@ -0,0 +1,46 @@
+package formatting
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+)
+
+const todoTxtDateFormat = "2006-01-02"
+
+var regexTodoTxtPriority = regexp.MustCompile(`^\([A-Z]\) `)
+
+// TodoTxt writes the current state of the todos of the projects as tasks in
+// the todo.txt format. Every task has the creation date and a +project tag,
+// completed tasks are marked with x and the completion date.
+func TodoTxt(writer io.Writer, projects *data.Projects) error {
+	buffer := new(bytes.Buffer)
+
+	for _, project := range projects.List() {
+		tag := "+" + strings.Join(strings.Fields(project.Name.String()), "_")
+
+		created := todosCreated(project)
+		for _, todo := range project.Todos() {
+			value := strings.Join(strings.Fields(todo.Value), " ")
+
+			// The priority of an open task has to be in front of the date.
+			if todo.Active && regexTodoTxtPriority.MatchString(value) {
+				buffer.WriteString(value[:4])
+				value = value[4:]
+			}
+
+			if !todo.Active {
+				buffer.WriteString("x " + todo.TimeStamp.Format(todoTxtDateFormat) + " ")
+			}
+
+			buffer.WriteString(created[todo.Key()].Format(todoTxtDateFormat) + " " +
+				value + " " + tag + "\n")
+		}
+	}
+
+	_, err := buffer.WriteTo(writer)
+	return err
+}
@@ -0,0 +1,32 @@
+package formatting
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func Test_TodoTxt(t *testing.T) {
+	expected := `(A) 2010-11-10 call someone +Test.Project.A
+x 2012-11-10 2011-11-10 todo todo +Test.Project.A
+`
+
+	project := testhelper.GetTestProject("A", 1, 0)
+	project.AddTodo(testhelper.GetTestTodo(0, "(A) call someone"))
+
+	todo := testhelper.GetTestTodo(1, "todo\ntodo")
+	project.AddTodo(todo)
+
+	todo.TimeStamp = todo.TimeStamp.AddDate(1, 0, 0)
+	todo.Active = false
+	project.AddTodo(todo)
+
+	projects := data.NewProjects()
+	projects.Add(project)
+
+	got := new(bytes.Buffer)
+	err := TodoTxt(got, &projects)
+	testhelper.CompareGotExpected(t, err, got.String(), expected)
+}
@@ -0,0 +1,100 @@
+package helper
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/juju/errgo"
+)
+
+// EditorHeaderPrefix marks the lines of the header that EditValue writes into
+// the file. All lines starting with this prefix are removed from the result.
+const EditorHeaderPrefix = "// lablog: "
+
+// DefaultEditor is used when neither $VISUAL nor $EDITOR are set.
+const DefaultEditor = "vi"
+
+// Editor returns the command that should be used to edit values. $VISUAL is
+// preferred over $EDITOR. The command can contain arguments separated by
+// whitespace.
+func Editor() string {
+	if editor := os.Getenv("VISUAL"); editor != "" {
+		return editor
+	}
+
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+
+	return DefaultEditor
+}
+
+// EditValue will open the editor on a temporary file that contains the given
+// header lines as comments followed by the given value. After the editor exits
+// the header is removed and the edited value is returned. An error is returned
+// if the editor command or the edited value is empty.
+func EditValue(header []string, value string) (string, error) {
+	fields := strings.Fields(Editor())
+	if len(fields) == 0 {
+		return "", errgo.New("the editor command is empty, set $VISUAL or $EDITOR")
+	}
+
+	file, err := ioutil.TempFile("", "lablog")
+	if err != nil {
+		return "", errgo.Notef(err, "can not create temporary file")
+	}
+	defer os.Remove(file.Name())
+
+	for _, line := range header {
+		_, err = file.WriteString(EditorHeaderPrefix + line + "\n")
+		if err != nil {
+			file.Close()
+			return "", errgo.Notef(err, "can not write header to temporary file")
+		}
+	}
+
+	_, err = file.WriteString(value)
+	file.Close()
+	if err != nil {
+		return "", errgo.Notef(err, "can not write value to temporary file")
+	}
+
+	command := exec.Command(fields[0], append(fields[1:], file.Name())...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+
+	err = command.Run()
+	if err != nil {
+		return "", errgo.Notef(err, "can not run editor "+Editor())
+	}
+
+	raw, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		return "", errgo.Notef(err, "can not read edited file")
+	}
+
+	edited := StripEditorHeader(string(raw))
+	if edited == "" {
+		return "", errgo.New("the edited value is empty, aborting")
+	}
+
+	return edited, nil
+}
+
+// StripEditorHeader removes all header lines from the given value and trims
+// surrounding whitespace.
+func StripEditorHeader(value string) string {
+	var lines []string
+	for _, line := range strings.Split(value, "\n") {
+		if strings.HasPrefix(line, EditorHeaderPrefix) {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,50 @@
+package helper
+
+import (
+	"os"
+	"testing"
+
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func Test_StripEditorHeader(t *testing.T) {
+	value := EditorHeaderPrefix + "project: Test.Project.A\n" +
+		EditorHeaderPrefix + "timestamp: 2010-11-10 23:00:00\n" +
+		"\n= Header\n// asciidoc comment\nnote note note\n\n"
+
+	expected := "= Header\n// asciidoc comment\nnote note note"
+
+	testhelper.CompareGotExpected(t, nil, StripEditorHeader(value), expected)
+}
+
+func Test_EditValue(t *testing.T) {
+	os.Setenv("VISUAL", "true")
+	defer os.Unsetenv("VISUAL")
+
+	got, err := EditValue([]string{"project: Test.Project.A"}, "note note note\n")
+	if err != nil {
+		t.Fatal("can not edit value: ", err)
+	}
+
+	testhelper.CompareGotExpected(t, err, got, "note note note")
+}
+
+func Test_EditValueEmpty(t *testing.T) {
+	os.Setenv("VISUAL", "true")
+	defer os.Unsetenv("VISUAL")
+
+	_, err := EditValue([]string{"project: Test.Project.A"}, "")
+	if err == nil {
+		t.Fatal("expected error for empty value")
+	}
+}
+
+func Test_EditValueEmptyEditor(t *testing.T) {
+	os.Setenv("VISUAL", " \t ")
+	defer os.Unsetenv("VISUAL")
+
+	_, err := EditValue([]string{"project: Test.Project.A"}, "note note note\n")
+	if err == nil {
+		t.Fatal("expected error for empty editor command")
+	}
+}
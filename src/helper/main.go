@@ -1,10 +1,13 @@
 package helper
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/index"
 	"github.com/AlexanderThaller/lablog/src/store"
 	"github.com/AlexanderThaller/lablog/src/vcs"
 
@@ -73,6 +76,15 @@ func RecordEntry(datadir string, project data.ProjectName, entry data.Entry, com
 		return errgo.Notef(err, "can not write note to data store")
 	}
 
+	// The entry is already recorded at this point so a broken index should not
+	// fail the command. The index will be refreshed on the next search.
+	if index.Exists(datadir) {
+		err := index.UpdateProject(datadir, store, project)
+		if err != nil {
+			log.Warning(errgo.Notef(err, "can not update search index"))
+		}
+	}
+
 	if commit {
 		err := vcs.Commit(datadir, project, entry)
 		if err != nil {
@@ -83,6 +95,153 @@ func RecordEntry(datadir string, project data.ProjectName, entry data.Entry, com
 	return nil
 }
 
+// UpdateEntries will replace the given old entries in the given project with
+// the updated entries at the same position and commit the change if commit is
+// true. The project file is only rewritten once for all entries.
+func UpdateEntries(datadir string, project data.ProjectName, old, updated []data.Entry, commit bool) error {
+	if len(old) != len(updated) {
+		return errgo.New("need the same amount of old and updated entries")
+	}
+
+	if len(old) == 0 {
+		return errgo.New("need at least one entry to update")
+	}
+
+	store, err := DefaultStore(datadir)
+	if err != nil {
+		return errgo.Notef(err, "can not get data store")
+	}
+
+	err = store.UpdateEntries(project, old, updated)
+	if err != nil {
+		return errgo.Notef(err, "can not update entries in data store")
+	}
+
+	err = changedEntries(datadir, store, project, "edit", updated[0], commit)
+	if err != nil {
+		return errgo.Notef(err, "can not record change of entries")
+	}
+
+	return nil
+}
+
+// DeleteEntries will remove the given entries from the given project and
+// commit the change if commit is true. The project file is only rewritten once
+// for all entries.
+func DeleteEntries(datadir string, project data.ProjectName, entries []data.Entry, commit bool) error {
+	if len(entries) == 0 {
+		return errgo.New("need at least one entry to delete")
+	}
+
+	store, err := DefaultStore(datadir)
+	if err != nil {
+		return errgo.Notef(err, "can not get data store")
+	}
+
+	err = store.DeleteEntries(project, entries)
+	if err != nil {
+		return errgo.Notef(err, "can not delete entries from data store")
+	}
+
+	err = changedEntries(datadir, store, project, "delete", entries[0], commit)
+	if err != nil {
+		return errgo.Notef(err, "can not record change of entries")
+	}
+
+	return nil
+}
+
+func changedEntries(datadir string, store store.Store, project data.ProjectName, action string, entry data.Entry, commit bool) error {
+	if index.Exists(datadir) {
+		err := index.UpdateProject(datadir, store, project)
+		if err != nil {
+			log.Warning(errgo.Notef(err, "can not update search index"))
+		}
+	}
+
+	if !commit {
+		return nil
+	}
+
+	message := project.String() + " - " + action + " " + entry.Type().String() + " - " +
+		entry.GetTimeStamp().Format(data.TimeStampFormat)
+
+	err := vcs.CommitMessage(datadir, message)
+	if err != nil {
+		return errgo.Notef(err, "can not commit change to repository")
+	}
+
+	return nil
+}
+
+// RecordMoves will record the moves of project files done with
+// store.MoveProjects and commit them with the given message if commit is true.
+func RecordMoves(datadir string, store store.Store, moves [][2]data.ProjectName, message string, commit bool) error {
+	if commit {
+		err := recordMoves(datadir, moves)
+		if err != nil {
+			return errgo.Notef(err, "can not record moved project files")
+		}
+	}
+
+	err := RecordChanges(datadir, store, message, commit)
+	if err != nil {
+		return errgo.Notef(err, "can not record move of projects")
+	}
+
+	return nil
+}
+
+// recordMoves tells the vcs backend which project files were moved so the
+// moves are recorded as renames.
+func recordMoves(datadir string, moves [][2]data.ProjectName) error {
+	backend, err := vcs.Open(datadir)
+	if err != nil {
+		return errgo.Notef(err, "can not open vcs backend")
+	}
+
+	for _, move := range moves {
+		from, err := filepath.Rel(datadir, store.ProjectPath(datadir, move[0]))
+		if err != nil {
+			return errgo.Notef(err, "can not get path of project file")
+		}
+
+		to, err := filepath.Rel(datadir, store.ProjectPath(datadir, move[1]))
+		if err != nil {
+			return errgo.Notef(err, "can not get path of project file")
+		}
+
+		err = backend.Move(from, to)
+		if err != nil {
+			return errgo.Notef(err, "can not move "+from+" to "+to)
+		}
+	}
+
+	return nil
+}
+
+// RecordChanges will refresh the search index after projects were changed in
+// the store and commit all changes with the given message if commit is true.
+func RecordChanges(datadir string, store store.Store, message string, commit bool) error {
+	if index.Exists(datadir) {
+		err := index.RefreshProjects(datadir, store)
+		if err != nil {
+			log.Warning(errgo.Notef(err, "can not refresh search index"))
+		}
+	}
+
+	if !commit {
+		return nil
+	}
+
+	err := vcs.CommitMessage(datadir, message)
+	if err != nil {
+		return errgo.Notef(err, "can not commit change to repository")
+	}
+
+	return nil
+}
+
 // ProjectNamesFromArgs will return all projects or all projects with
 // subprojects if the length of the args is not 0.
 func ProjectNamesFromArgs(store store.Store, args []string, showarchive bool) (data.Projects, error) {
@@ -113,6 +272,49 @@ func ProjectNamesFromArgs(store store.Store, args []string, showarchive bool) (d
 	return out, nil
 }
 
+// ProjectsFromArgs will return all projects or all projects with subprojects
+// if the length of the args is not 0 with all of their entries loaded from the
+// store. Projects that can not be loaded are logged and left out.
+func ProjectsFromArgs(store store.Store, args []string, showarchive bool) (data.Projects, error) {
+	if len(args) == 0 {
+		projects, err := store.GetProjects(showarchive)
+		err = logLoadErrors(err)
+		if err != nil {
+			return data.Projects{}, errgo.Notef(err, "can not get projects")
+		}
+
+		return projects, nil
+	}
+
+	projects, err := ProjectNamesFromArgs(store, args, showarchive)
+	if err != nil {
+		return data.Projects{}, errgo.Notef(err, "can not get list of projects")
+	}
+
+	err = logLoadErrors(store.PopulateProjects(&projects))
+	if err != nil {
+		return data.Projects{}, errgo.Notef(err, "can not populate projects with entries")
+	}
+
+	return projects, nil
+}
+
+// logLoadErrors logs the errors of projects that could not be loaded and
+// returns nil for them so the projects that were loaded can still be used.
+// Other errors are returned unchanged.
+func logLoadErrors(err error) error {
+	errs, ok := err.(store.LoadErrors)
+	if !ok {
+		return err
+	}
+
+	for _, err := range errs {
+		log.Warning(errgo.Notef(err, "can not load project"))
+	}
+
+	return nil
+}
+
 //ArgsToEntryValues will take the given args and try to parse the parameters and
 //flags to the values a normaly entry (note, todo, etc.) would need.
 func ArgsToEntryValues(args []string, addTimeStamp time.Time, rawTimeStamp string) (
@@ -151,7 +353,40 @@ func ArgsToTodo(args []string, addTimeStamp time.Time, rawTimeStamp string) (dat
 	todo := data.Todo{
 		TimeStamp: timestamp,
 		Value:     value,
+		ID:        data.NewTodoID(timestamp, value),
 	}
 
 	return project, todo, nil
 }
+
+// ExistingTodo returns the todo with the id of the todo in the project that
+// has the same value so a new record of it is folded together with the
+// existing todo. Active todos are preferred over todos that are already done.
+// The todo is returned unchanged if the project has no todo with the value.
+func ExistingTodo(datadir string, name data.ProjectName, todo data.Todo) (data.Todo, error) {
+	if _, err := os.Stat(store.ProjectPath(datadir, name)); os.IsNotExist(err) {
+		return todo, nil
+	}
+
+	store, err := DefaultStore(datadir)
+	if err != nil {
+		return todo, errgo.Notef(err, "can not get data store")
+	}
+
+	project, err := store.GetProject(name)
+	if err != nil {
+		return todo, errgo.Notef(err, "can not get project")
+	}
+
+	found := false
+	for _, existing := range project.Todos() {
+		if existing.Value != todo.Value || (found && !existing.Active) {
+			continue
+		}
+
+		todo.ID = existing.ID
+		found = true
+	}
+
+	return todo, nil
+}
@@ -0,0 +1,184 @@
+package helper
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/store"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+	"github.com/AlexanderThaller/lablog/src/vcs"
+)
+
+// memoryBackend makes vcs use a memory backend until the returned function
+// is called.
+func memoryBackend() (*vcs.Memory, func()) {
+	memory := vcs.NewMemory()
+
+	name := vcs.BackendName
+	vcs.Backends["memory"] = func(string) vcs.Backend { return memory }
+	vcs.BackendName = "memory"
+
+	return memory, func() {
+		delete(vcs.Backends, "memory")
+		vcs.BackendName = name
+	}
+}
+
+func tmpDataDir(t *testing.T) string {
+	datadir, err := ioutil.TempDir("", "helper_test")
+	if err != nil {
+		t.Fatal("can not open tmpdir: ", err)
+	}
+
+	return datadir
+}
+
+func messages(t *testing.T, backend vcs.Backend) []string {
+	log, err := backend.Log(0)
+	if err != nil {
+		t.Fatal("can not get log: ", err)
+	}
+
+	var out []string
+	for _, entry := range log {
+		out = append(out, entry.Message)
+	}
+
+	return out
+}
+
+func Test_RecordEntryCommit(t *testing.T) {
+	memory, restore := memoryBackend()
+	defer restore()
+	datadir := tmpDataDir(t)
+
+	project := data.ProjectName{"Test", "Project"}
+	note := testhelper.GetTestNote(0, "note")
+
+	err := RecordEntry(datadir, project, note, false)
+	if err != nil {
+		t.Fatal("can not record entry: ", err)
+	}
+
+	err = RecordEntry(datadir, project, note, true)
+	if err != nil {
+		t.Fatal("can not record entry: ", err)
+	}
+
+	testhelper.CompareGotExpected(t, nil, messages(t, memory), []string{
+		"Test.Project - note - " + note.GetTimeStamp().Format(data.TimeStampFormat),
+	})
+}
+
+func Test_RecordMovesCommit(t *testing.T) {
+	memory, restore := memoryBackend()
+	defer restore()
+	datadir := tmpDataDir(t)
+
+	for _, name := range []data.ProjectName{{"Test"}, {"Test", "Sub"}} {
+		err := RecordEntry(datadir, name, testhelper.GetTestNote(0, "note"), false)
+		if err != nil {
+			t.Fatal("can not record entry: ", err)
+		}
+	}
+
+	dataStore, err := DefaultStore(datadir)
+	if err != nil {
+		t.Fatal("can not get data store: ", err)
+	}
+
+	moves, err := store.MoveProjects(dataStore, data.ProjectName{"Test"}, data.ProjectName{"Moved"}, true)
+	if err != nil {
+		t.Fatal("can not move projects: ", err)
+	}
+
+	err = RecordMoves(datadir, dataStore, moves, "move", true)
+	if err != nil {
+		t.Fatal("can not record moves: ", err)
+	}
+
+	testhelper.CompareGotExpected(t, nil, memory.Moves(), [][2]string{
+		{"Test.csv", "Moved.csv"},
+		{"Test/Sub.csv", "Moved/Sub.csv"},
+	})
+	testhelper.CompareGotExpected(t, nil, messages(t, memory), []string{"move"})
+}
+
+func Test_ExistingTodo(t *testing.T) {
+	datadir := tmpDataDir(t)
+	project := data.ProjectName{"Test"}
+
+	done := testhelper.GetTestTodo(0, "todo")
+	done.ID = "done"
+	closed := done
+	closed.Active = false
+	closed.TimeStamp = closed.TimeStamp.Add(time.Minute)
+
+	active := testhelper.GetTestTodo(2, "todo")
+	active.ID = "active"
+
+	for _, todo := range []data.Todo{done, closed, active} {
+		err := RecordEntry(datadir, project, todo, false)
+		if err != nil {
+			t.Fatal("can not record todo: ", err)
+		}
+	}
+
+	tests := map[string]string{
+		"todo":  "active",
+		"other": "new",
+	}
+
+	for value, expected := range tests {
+		todo := data.Todo{Value: value, ID: "new"}
+
+		got, err := ExistingTodo(datadir, project, todo)
+		testhelper.CompareGotExpected(t, err, got.ID, expected)
+	}
+
+	got, err := ExistingTodo(datadir, data.ProjectName{"Missing"}, data.Todo{Value: "todo", ID: "new"})
+	testhelper.CompareGotExpected(t, err, got.ID, "new")
+}
+
+func Test_ProjectsFromArgsLoadErrors(t *testing.T) {
+	datadir := tmpDataDir(t)
+
+	note := testhelper.GetTestNote(0, "note")
+	err := RecordEntry(datadir, data.ProjectName{"Test"}, note, false)
+	if err != nil {
+		t.Fatal("can not record entry: ", err)
+	}
+
+	broken := store.ProjectPath(datadir, data.ProjectName{"Test", "Broken"})
+	err = os.MkdirAll(filepath.Dir(broken), 0755)
+	if err != nil {
+		t.Fatal("can not create folder: ", err)
+	}
+
+	err = ioutil.WriteFile(broken, []byte("garbage\n"), 0644)
+	if err != nil {
+		t.Fatal("can not write broken project file: ", err)
+	}
+
+	dataStore, err := DefaultStore(datadir)
+	if err != nil {
+		t.Fatal("can not get data store: ", err)
+	}
+
+	for _, args := range [][]string{nil, {"Test"}} {
+		projects, err := ProjectsFromArgs(dataStore, args, false)
+		if err != nil {
+			t.Fatal("expected the loaded projects but got: ", err)
+		}
+
+		var notes []data.Note
+		for _, project := range projects.List() {
+			notes = append(notes, project.Notes()...)
+		}
+		testhelper.CompareGotExpected(t, nil, notes, []data.Note{note})
+	}
+}
@@ -0,0 +1,107 @@
+package helper
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/jinzhu/now"
+	"github.com/juju/errgo"
+)
+
+var relativeTimeRegex = regexp.MustCompile(`^(\d+)([mhdw])$`)
+
+// ParseTimeFilter will parse the given raw value relative to the given
+// reference time. An empty value returns the zero time which means no filter
+// should be applied. Besides the formats supported by DefaultOrRawTimestamp the
+// named forms now, today, yesterday, this-week, last-week, this-month and
+// last-month and durations like 30m, 12h, 7d or 2w are supported.
+func ParseTimeFilter(raw string, reference time.Time) (time.Time, error) {
+	ref := now.New(reference)
+
+	switch raw {
+	case "":
+		return time.Time{}, nil
+	case "now":
+		return reference, nil
+	case "today":
+		return ref.BeginningOfDay(), nil
+	case "yesterday":
+		return ref.BeginningOfDay().AddDate(0, 0, -1), nil
+	case "this-week":
+		return ref.BeginningOfWeek(), nil
+	case "last-week":
+		return ref.BeginningOfWeek().AddDate(0, 0, -7), nil
+	case "this-month":
+		return ref.BeginningOfMonth(), nil
+	case "last-month":
+		return ref.BeginningOfMonth().AddDate(0, -1, 0), nil
+	}
+
+	matches := relativeTimeRegex.FindStringSubmatch(raw)
+	if matches != nil {
+		count, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return time.Time{}, errgo.Notef(err, "can not parse count of relative time")
+		}
+
+		switch matches[2] {
+		case "m":
+			return reference.Add(-time.Duration(count) * time.Minute), nil
+		case "h":
+			return reference.Add(-time.Duration(count) * time.Hour), nil
+		case "d":
+			return reference.AddDate(0, 0, -count), nil
+		case "w":
+			return reference.AddDate(0, 0, -count*7), nil
+		}
+	}
+
+	parsed, err := ref.Parse(raw)
+	if err != nil {
+		return time.Time{}, errgo.Notef(err, "can not parse time filter "+raw)
+	}
+
+	return parsed, nil
+}
+
+// FilterProjects will return a copy of the given projects which only contains
+// entries with a timestamp in the range between since and until. See
+// data.Project.Between for the details.
+func FilterProjects(projects data.Projects, since, until time.Time) data.Projects {
+	if since.IsZero() && until.IsZero() {
+		return projects
+	}
+
+	out := data.NewProjects()
+	for _, project := range projects.List() {
+		out.Add(project.Between(since, until))
+	}
+
+	return out
+}
+
+// DateFormat is the format of the dates returned by Dates.
+const DateFormat = "2006-01-02"
+
+// Dates returns the sorted list of days on which entries were recorded in the
+// given projects.
+func Dates(projects data.Projects) []string {
+	filter := make(map[string]struct{})
+	for _, project := range projects.List() {
+		for _, entry := range project.Entries {
+			filter[entry.GetTimeStamp().Format(DateFormat)] = struct{}{}
+		}
+	}
+
+	var dates []string
+	for date := range filter {
+		dates = append(dates, date)
+	}
+
+	sort.Strings(dates)
+
+	return dates
+}
@@ -0,0 +1,70 @@
+package helper
+
+import (
+	"testing"
+	"time"
+
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func Test_ParseTimeFilter(t *testing.T) {
+	reference := time.Date(2016, time.April, 14, 15, 30, 0, 0, time.Local)
+
+	tests := map[string]time.Time{
+		"":           time.Time{},
+		"now":        reference,
+		"today":      time.Date(2016, time.April, 14, 0, 0, 0, 0, time.Local),
+		"yesterday":  time.Date(2016, time.April, 13, 0, 0, 0, 0, time.Local),
+		"this-week":  time.Date(2016, time.April, 10, 0, 0, 0, 0, time.Local),
+		"last-week":  time.Date(2016, time.April, 3, 0, 0, 0, 0, time.Local),
+		"this-month": time.Date(2016, time.April, 1, 0, 0, 0, 0, time.Local),
+		"last-month": time.Date(2016, time.March, 1, 0, 0, 0, 0, time.Local),
+		"30m":        time.Date(2016, time.April, 14, 15, 0, 0, 0, time.Local),
+		"12h":        time.Date(2016, time.April, 14, 3, 30, 0, 0, time.Local),
+		"7d":         time.Date(2016, time.April, 7, 15, 30, 0, 0, time.Local),
+		"2w":         time.Date(2016, time.March, 31, 15, 30, 0, 0, time.Local),
+		"2016-01-02": time.Date(2016, time.January, 2, 0, 0, 0, 0, time.Local),
+	}
+
+	for input, expected := range tests {
+		got, err := ParseTimeFilter(input, reference)
+		if err != nil {
+			t.Fatal("can not parse time filter "+input+": ", err)
+		}
+
+		testhelper.CompareGotExpected(t, err, got, expected)
+	}
+}
+
+func Test_ParseTimeFilterInvalid(t *testing.T) {
+	_, err := ParseTimeFilter("not a time", time.Now())
+	if err == nil {
+		t.Fatal("expected an error for an invalid time filter")
+	}
+}
+
+func Test_FilterProjects(t *testing.T) {
+	projects := testhelper.GetTestProjects(5, 0, "A", "B")
+
+	since := time.Date(2011, time.January, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2013, time.November, 10, 23, 0, 0, 0, time.UTC)
+
+	expected := testhelper.GetTestProjects(0, 0, "A", "B")
+	for _, project := range expected.List() {
+		project.AddNote(testhelper.GetTestNote(1, "note note note"))
+		project.AddNote(testhelper.GetTestNote(2, "note note note"))
+		expected.Set(project)
+	}
+
+	got := FilterProjects(projects, since, until)
+	testhelper.CompareGotExpected(t, nil, got.List(), expected.List())
+}
+
+func Test_Dates(t *testing.T) {
+	projects := testhelper.GetTestProjects(3, 2, "A", "B")
+
+	expected := []string{"2010-11-10", "2011-11-10", "2012-11-10"}
+
+	got := Dates(projects)
+	testhelper.CompareGotExpected(t, nil, got, expected)
+}
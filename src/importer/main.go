@@ -0,0 +1,242 @@
+package importer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/store"
+	"github.com/juju/errgo"
+)
+
+// Record is an entry read by an importer together with the project it will be
+// recorded in.
+type Record struct {
+	Project data.ProjectName
+	Entry   data.Entry
+}
+
+// Options are passed to the importers.
+type Options struct {
+	// Project is used for entries that do not name a project themselves.
+	Project data.ProjectName
+	// Location is used for dates that do not have a timezone.
+	Location *time.Location
+}
+
+// Importer reads the records from the file at the given path.
+type Importer func(path string, options Options) ([]Record, error)
+
+// Importers contains the importers that can be selected by name.
+var Importers = map[string]Importer{
+	"todotxt":  TodoTxt,
+	"markdown": Markdown,
+	"files":    Files,
+}
+
+// Names returns the sorted names of all importers.
+func Names() []string {
+	var names []string
+	for name := range Importers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Import reads the records from all given paths with the importer that has
+// the given name. Directories are only supported by importers that read
+// folders.
+func Import(name string, paths []string, options Options) ([]Record, error) {
+	importer, ok := Importers[name]
+	if !ok {
+		return nil, errgo.New("the importer " + name + " is not known, use one of " +
+			strings.Join(Names(), ", "))
+	}
+
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+
+	var records []Record
+	for _, path := range paths {
+		read, err := importer(path, options)
+		if err != nil {
+			return nil, errgo.Notef(err, "can not import "+path)
+		}
+
+		records = append(records, read...)
+	}
+
+	return records, nil
+}
+
+// Deduplicate returns the records that are not already in the existing
+// projects or earlier in the records. Entries are the same if they have the
+// same type and value and were recorded on the same day, todos also need the
+// same state. Timestamps are moved forward by a millisecond until they are
+// unique in their project as entries are identified by type and timestamp.
+// Imported todos get the id of an existing todo with the same value so
+// finishing a todo and importing it again updates the existing todo.
+func Deduplicate(existing data.Projects, records []Record) ([]Record, int) {
+	seen := make(map[string]struct{})
+	used := make(map[string]struct{})
+	ids := make(map[string]string)
+
+	for _, project := range existing.List() {
+		name := project.Name.String()
+
+		for _, entry := range project.Entries {
+			seen[duplicateKey(name, entry)] = struct{}{}
+			used[timeStampKey(name, entry.Type(), entry.GetTimeStamp())] = struct{}{}
+
+			if todo, ok := entry.(data.Todo); ok && todo.ID != "" {
+				ids[name+"\x00"+todo.Value] = todo.ID
+			}
+		}
+	}
+
+	var out []Record
+	var duplicates int
+	for _, record := range records {
+		name := record.Project.String()
+
+		key := duplicateKey(name, record.Entry)
+		if _, ok := seen[key]; ok {
+			duplicates++
+			continue
+		}
+		seen[key] = struct{}{}
+
+		timestamp := record.Entry.GetTimeStamp()
+		for {
+			key := timeStampKey(name, record.Entry.Type(), timestamp)
+			if _, ok := used[key]; !ok {
+				used[key] = struct{}{}
+				break
+			}
+
+			timestamp = timestamp.Add(time.Millisecond)
+		}
+
+		switch entry := record.Entry.(type) {
+		case data.Note:
+			entry.TimeStamp = timestamp
+			record.Entry = entry
+		case data.Todo:
+			entry.TimeStamp = timestamp
+
+			id, ok := ids[name+"\x00"+entry.Value]
+			if !ok {
+				id = data.NewTodoID(timestamp, entry.Value)
+				ids[name+"\x00"+entry.Value] = id
+			}
+			entry.ID = id
+
+			record.Entry = entry
+		}
+
+		out = append(out, record)
+	}
+
+	return out, duplicates
+}
+
+// Save will add the records which are not already in the store to the store.
+// It returns the records that were added and the number of skipped
+// duplicates. Nothing is written if dryrun is true. See Deduplicate for which
+// records are duplicates.
+func Save(store store.Store, records []Record, dryrun bool) ([]Record, int, error) {
+	existing, err := store.GetProjects(true)
+	if err != nil {
+		return nil, 0, errgo.Notef(err, "can not get projects")
+	}
+
+	records, duplicates := Deduplicate(existing, records)
+	if dryrun {
+		return records, duplicates, nil
+	}
+
+	for _, record := range records {
+		err = store.AddEntry(record.Project, record.Entry)
+		if err != nil {
+			return nil, 0, errgo.Notef(err, "can not write entry to data store")
+		}
+	}
+
+	return records, duplicates, nil
+}
+
+func duplicateKey(project string, entry data.Entry) string {
+	values := entry.Values()
+
+	// Replace the timestamp with the day and drop the id of todos.
+	values[1] = entry.GetTimeStamp().Format("2006-01-02")
+	if entry.Type() == data.EntryTypeTodo && len(values) == 5 {
+		values = values[:4]
+	}
+
+	return project + "\x00" + strings.Join(values, "\x00")
+}
+
+func timeStampKey(project string, etype data.EntryType, timestamp time.Time) string {
+	return project + "\x00" + etype.String() + "\x00" + timestamp.UTC().Format(data.TimeStampFormat)
+}
+
+// Files reads the file at the given path as one note with the modification
+// time of the file as timestamp. Folders are read recursively, hidden files
+// and folders are skipped.
+func Files(path string, options Options) ([]Record, error) {
+	if len(options.Project) == 0 {
+		return nil, errgo.New("need a project to import files into")
+	}
+
+	var records []Record
+	err := filepath.Walk(path, func(current string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if current != path && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(current)
+		if err != nil {
+			return errgo.Notef(err, "can not read file "+current)
+		}
+
+		value := strings.TrimSpace(string(content))
+		if value == "" {
+			return nil
+		}
+
+		records = append(records, Record{
+			Project: options.Project,
+			Entry: data.Note{
+				TimeStamp: info.ModTime().In(options.Location),
+				Value:     value,
+			},
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, errgo.Notef(err, "can not walk path")
+	}
+
+	return records, nil
+}
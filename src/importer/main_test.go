@@ -0,0 +1,131 @@
+package importer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/store"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func tmpFile(t *testing.T, name, content string) string {
+	tmpdir, err := ioutil.TempDir("", "importer_test")
+	if err != nil {
+		t.Fatal("can not open tmpdir: ", err)
+	}
+
+	path := filepath.Join(tmpdir, name)
+	err = ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal("can not write tmpfile: ", err)
+	}
+
+	return path
+}
+
+func Test_Deduplicate(t *testing.T) {
+	project := testhelper.GetTestProject("A", 1, 1)
+
+	existing := data.NewProjects()
+	existing.Add(project)
+
+	note := testhelper.GetTestNote(0, "note note note")
+	todo := testhelper.GetTestTodo(0, "todo todo todo")
+
+	sameDay := note
+	sameDay.TimeStamp = note.TimeStamp.Add(-time.Hour)
+
+	collision := note
+	collision.Value = "other note"
+
+	done := todo
+	done.ID = ""
+	done.Active = false
+	done.TimeStamp = todo.TimeStamp.AddDate(0, 0, 1)
+
+	records := []Record{
+		{Project: project.Name, Entry: sameDay},
+		{Project: project.Name, Entry: collision},
+		{Project: project.Name, Entry: collision},
+		{Project: project.Name, Entry: done},
+	}
+
+	collision.TimeStamp = collision.TimeStamp.Add(time.Millisecond)
+	done.ID = todo.ID
+
+	expected := []Record{
+		{Project: project.Name, Entry: collision},
+		{Project: project.Name, Entry: done},
+	}
+
+	got, duplicates := Deduplicate(existing, records)
+	testhelper.CompareGotExpected(t, nil, got, expected)
+	testhelper.CompareGotExpected(t, nil, duplicates, 2)
+}
+
+func Test_Save(t *testing.T) {
+	datadir, err := ioutil.TempDir("", "importer_test")
+	if err != nil {
+		t.Fatal("can not open tmpdir: ", err)
+	}
+
+	folderstore, err := store.NewFolderStore(datadir)
+	if err != nil {
+		t.Fatal("can not create new store: ", err)
+	}
+
+	note := testhelper.GetTestNote(0, "note")
+	records := []Record{
+		{Project: data.ProjectName{"Test"}, Entry: note},
+		{Project: data.ProjectName{"Test"}, Entry: note},
+	}
+
+	got, duplicates, err := Save(folderstore, records, true)
+	testhelper.CompareGotExpected(t, err, got, records[:1])
+	testhelper.CompareGotExpected(t, nil, duplicates, 1)
+
+	projects, err := folderstore.ListProjects(true)
+	testhelper.CompareGotExpected(t, err, len(projects.List()), 0)
+
+	for i := 0; i != 2; i++ {
+		got, _, err = Save(folderstore, records, false)
+		testhelper.CompareGotExpected(t, err, len(got), 1-i)
+	}
+
+	project, err := folderstore.GetProject(data.ProjectName{"Test"})
+	testhelper.CompareGotExpected(t, err, project.Notes(), []data.Note{note})
+}
+
+func Test_Files(t *testing.T) {
+	path := tmpFile(t, "note.txt", "\nfile note\n")
+
+	modtime := time.Date(2016, time.May, 20, 12, 0, 0, 0, time.UTC)
+	err := os.Chtimes(path, modtime, modtime)
+	if err != nil {
+		t.Fatal("can not set modification time: ", err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(filepath.Dir(path), ".hidden"), []byte("hidden"), 0644)
+	if err != nil {
+		t.Fatal("can not write hidden file: ", err)
+	}
+
+	project := data.ProjectName{"Test", "Files"}
+	expected := []Record{
+		{Project: project, Entry: data.Note{TimeStamp: modtime, Value: "file note"}},
+	}
+
+	got, err := Import("files", []string{filepath.Dir(path)}, Options{Project: project, Location: time.UTC})
+	testhelper.CompareGotExpected(t, err, got, expected)
+}
+
+func Test_ImportUnknown(t *testing.T) {
+	_, err := Import("unknown", nil, Options{})
+	if err == nil {
+		t.Fatal("expected an error for an unknown importer")
+	}
+}
@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/juju/errgo"
+)
+
+var regexMarkdownDate = regexp.MustCompile(`^#{1,6}\s+(\d{4}-\d{2}-\d{2})(?:[ T](\d{2}:\d{2}(?::\d{2})?))?(?:\s+(.*?))?\s*#*\s*$`)
+
+// Markdown reads a journal written in markdown and returns a note for every
+// heading that starts with a date. The note contains everything up to the next
+// heading with a date, the rest of the heading after the date becomes the
+// first line of the note. The date can be followed by a time like in
+// "## 2016-05-20 14:30". Text before the first date is ignored and headings in
+// fenced code blocks are not used. All notes are recorded in the project from
+// the options.
+func Markdown(path string, options Options) ([]Record, error) {
+	if len(options.Project) == 0 {
+		return nil, errgo.New("need a project to import the journal into")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errgo.Notef(err, "can not open file")
+	}
+	defer file.Close()
+
+	var records []Record
+	var current *data.Note
+	var lines []string
+
+	flush := func() {
+		if current != nil {
+			current.Value = strings.TrimSpace(strings.Join(lines, "\n"))
+			if current.Value != "" {
+				records = append(records, Record{Project: options.Project, Entry: *current})
+			}
+		}
+
+		current, lines = nil, nil
+	}
+
+	fenced := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			fenced = !fenced
+		}
+
+		match := regexMarkdownDate.FindStringSubmatch(line)
+		if fenced || match == nil {
+			lines = append(lines, line)
+			continue
+		}
+
+		timestamp, err := parseMarkdownDate(match[1], match[2], options.Location)
+		if err != nil {
+			return nil, errgo.Notef(err, "can not parse date of heading "+line)
+		}
+
+		flush()
+		current = &data.Note{TimeStamp: timestamp}
+		if match[3] != "" {
+			lines = append(lines, match[3])
+		}
+	}
+	flush()
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, errgo.Notef(err, "can not read file")
+	}
+
+	return records, nil
+}
+
+func parseMarkdownDate(date, clock string, location *time.Location) (time.Time, error) {
+	switch len(clock) {
+	case 0:
+		return time.ParseInLocation("2006-01-02", date, location)
+	case 5:
+		return time.ParseInLocation("2006-01-02 15:04", date+" "+clock, location)
+	default:
+		return time.ParseInLocation("2006-01-02 15:04:05", date+" "+clock, location)
+	}
+}
@@ -0,0 +1,46 @@
+package importer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func Test_Markdown(t *testing.T) {
+	path := tmpFile(t, "journal.md", `# Journal
+
+Ignored text.
+
+## 2016-05-01
+
+First day.
+
+### Details
+More text.
+
+## 2016-05-02 14:30 Meeting
+
+`+"```"+`
+# 2016-05-03 not a heading
+`+"```"+`
+
+## 2016-05-04
+`)
+
+	project := data.ProjectName{"Journal"}
+	expected := []Record{
+		{Project: project, Entry: data.Note{
+			TimeStamp: time.Date(2016, time.May, 1, 0, 0, 0, 0, time.UTC),
+			Value:     "First day.\n\n### Details\nMore text.",
+		}},
+		{Project: project, Entry: data.Note{
+			TimeStamp: time.Date(2016, time.May, 2, 14, 30, 0, 0, time.UTC),
+			Value:     "Meeting\n\n```\n# 2016-05-03 not a heading\n```",
+		}},
+	}
+
+	got, err := Markdown(path, Options{Project: project, Location: time.UTC})
+	testhelper.CompareGotExpected(t, err, got, expected)
+}
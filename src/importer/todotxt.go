@@ -0,0 +1,153 @@
+package importer
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/juju/errgo"
+)
+
+const todoTxtDateFormat = "2006-01-02"
+
+var (
+	regexTodoTxtPriority = regexp.MustCompile(`^\(([A-Z])\)$`)
+	regexTodoTxtDate     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// TodoTxt reads the tasks of a todo.txt file as todos. The first +project of
+// a task is the project of the todo, dots in it separate subprojects. Tasks
+// without a project are recorded in the project from the options. The
+// priority and the contexts are kept in the value of the todo. Tasks get the
+// creation date as timestamp and completed tasks get an additional inactive
+// todo with the completion date. Tasks without a date use the modification
+// time of the file.
+func TodoTxt(path string, options Options) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errgo.Notef(err, "can not open file")
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, errgo.Notef(err, "can not get file info")
+	}
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		read, err := parseTodoTxt(line, info.ModTime(), options)
+		if err != nil {
+			return nil, errgo.Notef(err, "can not parse line "+strconv.Itoa(number))
+		}
+
+		records = append(records, read...)
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, errgo.Notef(err, "can not read file")
+	}
+
+	return records, nil
+}
+
+func parseTodoTxt(line string, modtime time.Time, options Options) ([]Record, error) {
+	fields := strings.Fields(line)
+
+	completed := false
+	if fields[0] == "x" {
+		completed = true
+		fields = fields[1:]
+	}
+
+	var priority string
+	if len(fields) != 0 && regexTodoTxtPriority.MatchString(fields[0]) {
+		priority = fields[0]
+		fields = fields[1:]
+	}
+
+	// A completed task starts with the completion date followed by the
+	// creation date, an open task only has the creation date.
+	var dates []time.Time
+	for len(fields) != 0 && len(dates) < 2 && regexTodoTxtDate.MatchString(fields[0]) {
+		date, err := time.ParseInLocation(todoTxtDateFormat, fields[0], options.Location)
+		if err != nil {
+			return nil, errgo.Notef(err, "can not parse date")
+		}
+
+		dates = append(dates, date)
+		fields = fields[1:]
+	}
+
+	var created, finished time.Time
+	switch {
+	case completed && len(dates) == 2:
+		finished, created = dates[0], dates[1]
+	case completed && len(dates) == 1:
+		finished = dates[0]
+	case completed:
+		finished = modtime.In(options.Location)
+	case len(dates) != 0:
+		created = dates[0]
+	default:
+		created = modtime.In(options.Location)
+	}
+
+	project := options.Project
+	var value []string
+	if priority != "" {
+		value = append(value, priority)
+	}
+
+	found := false
+	for _, field := range fields {
+		if !found && strings.HasPrefix(field, "+") && len(field) > 1 {
+			name, err := data.ParseProjectName(field[1:])
+			if err != nil {
+				return nil, errgo.Notef(err, "can not parse project name")
+			}
+
+			project = name
+			found = true
+			continue
+		}
+
+		value = append(value, field)
+	}
+
+	if len(project) == 0 {
+		return nil, errgo.New("task has no project and no default project is set")
+	}
+
+	if len(value) == 0 {
+		return nil, errgo.New("task has no description")
+	}
+
+	todo := data.Todo{Value: strings.Join(value, " ")}
+
+	var records []Record
+	if !created.IsZero() {
+		todo.Active = true
+		todo.TimeStamp = created
+		records = append(records, Record{Project: project, Entry: todo})
+	}
+
+	if completed {
+		todo.Active = false
+		todo.TimeStamp = finished
+		records = append(records, Record{Project: project, Entry: todo})
+	}
+
+	return records, nil
+}
@@ -0,0 +1,45 @@
+package importer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func Test_TodoTxt(t *testing.T) {
+	path := tmpFile(t, "todo.txt", `(A) 2016-05-01 Call mom +Family.Parents @phone
+x 2016-05-03 2016-05-02 Buy milk +Shopping +Errands
+
+2016-05-04 Read book
+`)
+
+	date := func(day int) time.Time {
+		return time.Date(2016, time.May, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	inbox := data.ProjectName{"Inbox"}
+	expected := []Record{
+		{Project: data.ProjectName{"Family", "Parents"}, Entry: data.Todo{
+			Active: true, TimeStamp: date(1), Value: "(A) Call mom @phone"}},
+		{Project: data.ProjectName{"Shopping"}, Entry: data.Todo{
+			Active: true, TimeStamp: date(2), Value: "Buy milk +Errands"}},
+		{Project: data.ProjectName{"Shopping"}, Entry: data.Todo{
+			Active: false, TimeStamp: date(3), Value: "Buy milk +Errands"}},
+		{Project: inbox, Entry: data.Todo{
+			Active: true, TimeStamp: date(4), Value: "Read book"}},
+	}
+
+	got, err := TodoTxt(path, Options{Project: inbox, Location: time.UTC})
+	testhelper.CompareGotExpected(t, err, got, expected)
+}
+
+func Test_TodoTxtNoProject(t *testing.T) {
+	path := tmpFile(t, "todo.txt", "2016-05-04 Read book\n")
+
+	_, err := TodoTxt(path, Options{Location: time.UTC})
+	if err == nil {
+		t.Fatal("expected an error for a task without a project")
+	}
+}
@@ -0,0 +1,352 @@
+package index
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/store"
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errgo"
+)
+
+// Folder is the name of the folder inside of the store.MetaFolder of the
+// datadir the index is saved in.
+const Folder = ".index"
+
+// FileName is the name of the index file inside of the index folder.
+const FileName = "index.json"
+
+// Version is the version of the index format. Indexes with a different
+// version will be rebuilt.
+const Version = 1
+
+// Index is an inverted index which maps the tokens of all note and todo values
+// to the projects that contain them. It is only used to find the projects that
+// can match a query, the matching itself is still done on the entries.
+type Index struct {
+	Version  int
+	Projects map[string]ProjectState
+	Tokens   map[string][]string
+
+	datadir string
+	store   store.Store
+	changed bool
+}
+
+// ProjectState is used to detect if the file of a project changed since it
+// was indexed.
+type ProjectState struct {
+	ModTime time.Time
+	Size    int64
+}
+
+// Open loads the index from the given datadir. If there is no index yet or the
+// index has a different version an empty index is returned.
+func Open(datadir string, store store.Store) (*Index, error) {
+	index := newIndex(datadir, store)
+
+	raw, err := ioutil.ReadFile(Path(datadir))
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, errgo.Notef(err, "can not read index file")
+	}
+
+	err = json.Unmarshal(raw, index)
+	if err != nil {
+		return nil, errgo.Notef(err, "can not decode index file")
+	}
+
+	if index.Version != Version {
+		log.Debug("Index version ", index.Version, " is outdated")
+		return newIndex(datadir, store), nil
+	}
+
+	return index, nil
+}
+
+// Exists returns true if there is an index saved in the given datadir.
+func Exists(datadir string) bool {
+	_, err := os.Stat(Path(datadir))
+	return err == nil
+}
+
+// Path returns the path of the index file for the given datadir.
+func Path(datadir string) string {
+	return filepath.Join(indexFolder(datadir), FileName)
+}
+
+// indexFolder returns the folder of the index inside the meta folder of the
+// given datadir.
+func indexFolder(datadir string) string {
+	return filepath.Join(datadir, store.MetaFolder, Folder)
+}
+
+// Rebuild removes the index from the given datadir and indexes all projects
+// again.
+func Rebuild(datadir string, store store.Store) (*Index, error) {
+	err := os.RemoveAll(indexFolder(datadir))
+	if err != nil {
+		return nil, errgo.Notef(err, "can not remove old index")
+	}
+
+	index := newIndex(datadir, store)
+	index.changed = true
+
+	err = index.Refresh()
+	if err != nil {
+		return nil, errgo.Notef(err, "can not index projects")
+	}
+
+	return index, nil
+}
+
+func newIndex(datadir string, store store.Store) *Index {
+	return &Index{
+		Version:  Version,
+		Projects: make(map[string]ProjectState),
+		Tokens:   make(map[string][]string),
+
+		datadir: datadir,
+		store:   store,
+	}
+}
+
+// Refresh reindexes all projects which files changed since they were indexed
+// and removes projects that do not exist anymore. The index is saved if
+// anything changed.
+func (index *Index) Refresh() error {
+	projects, err := index.store.ListProjects(true)
+	if err != nil {
+		return errgo.Notef(err, "can not get list of projects")
+	}
+
+	existing := make(map[string]struct{})
+	for _, project := range projects.List() {
+		name := project.Name.String()
+		existing[name] = struct{}{}
+
+		state, err := index.state(project.Name)
+		if err != nil {
+			return errgo.Notef(err, "can not get state of project "+name)
+		}
+
+		indexed, ok := index.Projects[name]
+		if ok && indexed.ModTime.Equal(state.ModTime) && indexed.Size == state.Size {
+			continue
+		}
+
+		log.Debug("Reindexing stale project ", name)
+		err = index.update(project.Name, state)
+		if err != nil {
+			return errgo.Notef(err, "can not index project "+name)
+		}
+	}
+
+	for name := range index.Projects {
+		if _, ok := existing[name]; !ok {
+			log.Debug("Removing deleted project ", name)
+			index.remove(name)
+		}
+	}
+
+	return index.save()
+}
+
+// Update reindexes the given project and saves the index.
+func (index *Index) Update(name data.ProjectName) error {
+	state, err := index.state(name)
+	if err != nil {
+		return errgo.Notef(err, "can not get state of project")
+	}
+
+	err = index.update(name, state)
+	if err != nil {
+		return errgo.Notef(err, "can not index project")
+	}
+
+	return index.save()
+}
+
+// Candidates returns the names of all projects that can contain the given
+// value. A project is a candidate if for every token of the value it contains
+// a token that contains the token of the value. The check ignores the case so
+// the result is a superset of the projects that match case sensitive.
+func (index *Index) Candidates(value string) []data.ProjectName {
+	var candidates map[string]struct{}
+
+	for _, token := range Tokenize(value) {
+		found := make(map[string]struct{})
+		for indexed, names := range index.Tokens {
+			if !strings.Contains(indexed, token) {
+				continue
+			}
+
+			for _, name := range names {
+				if candidates == nil {
+					found[name] = struct{}{}
+					continue
+				}
+
+				if _, ok := candidates[name]; ok {
+					found[name] = struct{}{}
+				}
+			}
+		}
+
+		candidates = found
+	}
+
+	// A value without any tokens can not be narrowed down.
+	if candidates == nil {
+		candidates = make(map[string]struct{})
+		for name := range index.Projects {
+			candidates[name] = struct{}{}
+		}
+	}
+
+	var names []string
+	for name := range candidates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out []data.ProjectName
+	for _, name := range names {
+		parsed, _ := data.ParseProjectName(name)
+		out = append(out, parsed)
+	}
+
+	return out
+}
+
+// Tokenize splits the given value into lowercase tokens of letters and
+// numbers.
+func Tokenize(value string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	return fields
+}
+
+func (index *Index) state(name data.ProjectName) (ProjectState, error) {
+	info, err := os.Stat(store.ProjectPath(index.datadir, name))
+	if err != nil {
+		return ProjectState{}, errgo.Notef(err, "can not stat project file")
+	}
+
+	return ProjectState{ModTime: info.ModTime(), Size: info.Size()}, nil
+}
+
+func (index *Index) update(name data.ProjectName, state ProjectState) error {
+	project, err := index.store.GetProject(name)
+	if err != nil {
+		return errgo.Notef(err, "can not get project")
+	}
+
+	index.remove(name.String())
+
+	tokens := make(map[string]struct{})
+	for _, note := range project.Notes() {
+		for _, token := range Tokenize(note.Value) {
+			tokens[token] = struct{}{}
+		}
+	}
+
+	for _, todo := range project.Todos() {
+		for _, token := range Tokenize(todo.Value) {
+			tokens[token] = struct{}{}
+		}
+	}
+
+	for token := range tokens {
+		index.Tokens[token] = append(index.Tokens[token], name.String())
+	}
+
+	index.Projects[name.String()] = state
+	index.changed = true
+
+	return nil
+}
+
+func (index *Index) remove(name string) {
+	if _, ok := index.Projects[name]; !ok {
+		return
+	}
+
+	for token, names := range index.Tokens {
+		var kept []string
+		for _, indexed := range names {
+			if indexed != name {
+				kept = append(kept, indexed)
+			}
+		}
+
+		if len(kept) == 0 {
+			delete(index.Tokens, token)
+			continue
+		}
+
+		index.Tokens[token] = kept
+	}
+
+	delete(index.Projects, name)
+	index.changed = true
+}
+
+// save writes the index to a temporary file and renames it afterwards so
+// readers never see a partially written index.
+func (index *Index) save() error {
+	if !index.changed {
+		return nil
+	}
+
+	folder := indexFolder(index.datadir)
+	err := os.MkdirAll(folder, 0755)
+	if err != nil {
+		return errgo.Notef(err, "can not create index folder")
+	}
+
+	// The index can always be rebuilt from the data so it should not end up
+	// in the repository.
+	err = ioutil.WriteFile(filepath.Join(folder, ".gitignore"), []byte("*\n"), 0644)
+	if err != nil {
+		return errgo.Notef(err, "can not write gitignore for index folder")
+	}
+
+	raw, err := json.Marshal(index)
+	if err != nil {
+		return errgo.Notef(err, "can not encode index")
+	}
+
+	file, err := ioutil.TempFile(folder, FileName)
+	if err != nil {
+		return errgo.Notef(err, "can not create temporary index file")
+	}
+
+	_, err = file.Write(raw)
+	file.Close()
+	if err != nil {
+		os.Remove(file.Name())
+		return errgo.Notef(err, "can not write temporary index file")
+	}
+
+	err = os.Rename(file.Name(), Path(index.datadir))
+	if err != nil {
+		os.Remove(file.Name())
+		return errgo.Notef(err, "can not move temporary index file")
+	}
+
+	index.changed = false
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package index
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/store"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func tmp_index(t *testing.T) (string, store.Store) {
+	tmpdir, err := ioutil.TempDir("/tmp/", "index_test")
+	if err != nil {
+		t.Fatal("can not open tmpdir: ", err)
+	}
+
+	folderstore, err := store.NewFolderStore(tmpdir)
+	if err != nil {
+		t.Fatal("can not create new store: ", err)
+	}
+
+	return tmpdir, folderstore
+}
+
+func Test_Tokenize(t *testing.T) {
+	got := Tokenize("Hello, World! This is\nnote-1")
+	expected := []string{"hello", "world", "this", "is", "note", "1"}
+
+	testhelper.CompareGotExpected(t, nil, got, expected)
+}
+
+func Test_RebuildCandidates(t *testing.T) {
+	datadir, folderstore := tmp_index(t)
+
+	projects := testhelper.GetTestProjects(1, 0, "A", "B")
+	for _, project := range projects.List() {
+		err := folderstore.PutProject(project)
+		if err != nil {
+			t.Fatal("can not put test project into store: ", err)
+		}
+	}
+
+	other := testhelper.GetTestProject("C", 0, 0)
+	other.AddNote(testhelper.GetTestNote(0, "Something Else"))
+	err := folderstore.PutProject(other)
+	if err != nil {
+		t.Fatal("can not put test project into store: ", err)
+	}
+
+	_, err = Rebuild(datadir, folderstore)
+	if err != nil {
+		t.Fatal("can not rebuild index: ", err)
+	}
+
+	idx, err := Open(datadir, folderstore)
+	if err != nil {
+		t.Fatal("can not open index: ", err)
+	}
+
+	expected := []data.ProjectName{
+		data.ProjectName{"Test", "Project", "A"},
+		data.ProjectName{"Test", "Project", "B"},
+	}
+	testhelper.CompareGotExpected(t, nil, idx.Candidates("ote no"), expected)
+
+	expected = []data.ProjectName{data.ProjectName{"Test", "Project", "C"}}
+	testhelper.CompareGotExpected(t, nil, idx.Candidates("something"), expected)
+
+	testhelper.CompareGotExpected(t, nil, len(idx.Candidates("missing")), 0)
+}
+
+func Test_RefreshStale(t *testing.T) {
+	datadir, folderstore := tmp_index(t)
+
+	project := testhelper.GetTestProject("A", 1, 0)
+	err := folderstore.PutProject(project)
+	if err != nil {
+		t.Fatal("can not put test project into store: ", err)
+	}
+
+	_, err = Rebuild(datadir, folderstore)
+	if err != nil {
+		t.Fatal("can not rebuild index: ", err)
+	}
+
+	err = folderstore.AddEntry(project.Name, testhelper.GetTestNote(1, "fresh value"))
+	if err != nil {
+		t.Fatal("can not add entry to store: ", err)
+	}
+
+	// Make sure the modification time changes even on filesystems with a
+	// coarse resolution.
+	future := time.Now().Add(time.Hour)
+	err = os.Chtimes(store.ProjectPath(datadir, project.Name), future, future)
+	if err != nil {
+		t.Fatal("can not change modification time: ", err)
+	}
+
+	idx, err := Open(datadir, folderstore)
+	if err != nil {
+		t.Fatal("can not open index: ", err)
+	}
+
+	testhelper.CompareGotExpected(t, nil, len(idx.Candidates("fresh")), 0)
+
+	err = idx.Refresh()
+	if err != nil {
+		t.Fatal("can not refresh index: ", err)
+	}
+
+	testhelper.CompareGotExpected(t, nil, idx.Candidates("fresh"), []data.ProjectName{project.Name})
+}
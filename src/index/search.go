@@ -0,0 +1,83 @@
+package index
+
+import (
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/search"
+	"github.com/AlexanderThaller/lablog/src/store"
+	"github.com/juju/errgo"
+)
+
+// UpdateProject will reindex the given project in the search index of the
+// given datadir.
+func UpdateProject(datadir string, store store.Store, project data.ProjectName) error {
+	index, err := Open(datadir, store)
+	if err != nil {
+		return errgo.Notef(err, "can not open search index")
+	}
+
+	err = index.Update(project)
+	if err != nil {
+		return errgo.Notef(err, "can not update project in search index")
+	}
+
+	return nil
+}
+
+// RefreshProjects will reindex all changed projects in the search index of
+// the given datadir.
+func RefreshProjects(datadir string, store store.Store) error {
+	index, err := Open(datadir, store)
+	if err != nil {
+		return errgo.Notef(err, "can not open search index")
+	}
+
+	err = index.Refresh()
+	if err != nil {
+		return errgo.Notef(err, "can not refresh search index")
+	}
+
+	return nil
+}
+
+// Search will search the given projects for the query. Plain queries use the
+// search index of the datadir to only load the projects that can match. Regex
+// queries always search all given projects.
+func Search(datadir string, store store.Store, query search.Query, projects data.Projects) ([]search.Result, error) {
+	matcher, err := search.NewMatcher(query)
+	if err != nil {
+		return nil, errgo.Notef(err, "can not create matcher for query")
+	}
+
+	if !query.Regex {
+		index, err := Open(datadir, store)
+		if err != nil {
+			return nil, errgo.Notef(err, "can not open search index")
+		}
+
+		err = index.Refresh()
+		if err != nil {
+			return nil, errgo.Notef(err, "can not refresh search index")
+		}
+
+		candidates := make(map[string]struct{})
+		for _, name := range index.Candidates(query.Value) {
+			candidates[name.String()] = struct{}{}
+		}
+
+		filtered := data.NewProjects()
+		for _, project := range projects.List() {
+			if _, ok := candidates[project.Name.String()]; ok {
+				filtered.Add(project)
+			}
+		}
+
+		projects = filtered
+	}
+
+	err = store.PopulateProjects(&projects)
+	if err != nil {
+		return nil, errgo.Notef(err, "can not populate projects with entries")
+	}
+
+	return search.Projects(matcher, projects), nil
+}
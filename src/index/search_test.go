@@ -0,0 +1,53 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/search"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func Test_Search(t *testing.T) {
+	datadir, folderstore := tmp_index(t)
+
+	for name, value := range map[string]string{"A": "hello world", "B": "other"} {
+		err := folderstore.AddEntry(data.ProjectName{name}, testhelper.GetTestNote(0, value))
+		if err != nil {
+			t.Fatal("can not add entry: ", err)
+		}
+	}
+
+	tests := map[search.Query][]string{
+		{Value: "world"}:                   {"A"},
+		{Value: "WORLD", IgnoreCase: true}: {"A"},
+		{Value: "o.he", Regex: true}:       {"B"},
+		{Value: "missing"}:                 nil,
+	}
+
+	for query, expected := range tests {
+		projects, err := folderstore.ListProjects(false)
+		if err != nil {
+			t.Fatal("can not list projects: ", err)
+		}
+
+		results, err := Search(datadir, folderstore, query, projects)
+		if err != nil {
+			t.Fatal("can not search projects: ", err)
+		}
+
+		var got []string
+		for _, result := range results {
+			got = append(got, result.Project.String())
+		}
+
+		testhelper.CompareGotExpected(t, nil, got, expected)
+	}
+
+	_, err := os.Stat(filepath.Join(datadir, ".lablog", ".index", "index.json"))
+	if err != nil {
+		t.Fatal("expected the search to create the index in the meta folder: ", err)
+	}
+}
@@ -0,0 +1,181 @@
+package search
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/juju/errgo"
+)
+
+// SnippetContext is the number of characters that will be shown before and
+// after a match in the snippet of a result.
+const SnippetContext = 40
+
+// Query describes what to search for. By default the value is matched as a
+// plain substring.
+type Query struct {
+	Value      string
+	Regex      bool
+	IgnoreCase bool
+}
+
+// Result is a single entry that matched the query.
+type Result struct {
+	Project data.ProjectName
+	Entry   data.Entry
+	Snippet Snippet
+}
+
+// Snippet is the part of the entry value around the first match. Before, Match
+// and After can be concatenated to get the full snippet.
+type Snippet struct {
+	Before string
+	Match  string
+	After  string
+}
+
+func (snippet Snippet) String() string {
+	return snippet.Before + snippet.Match + snippet.After
+}
+
+// Matcher matches entry values against a compiled query.
+type Matcher struct {
+	regex *regexp.Regexp
+}
+
+// NewMatcher will compile the given query into a matcher.
+func NewMatcher(query Query) (Matcher, error) {
+	if query.Value == "" {
+		return Matcher{}, errgo.New("the search query can not be empty")
+	}
+
+	expression := query.Value
+	if !query.Regex {
+		expression = regexp.QuoteMeta(expression)
+	}
+
+	if query.IgnoreCase {
+		expression = "(?i)" + expression
+	}
+
+	regex, err := regexp.Compile(expression)
+	if err != nil {
+		return Matcher{}, errgo.Notef(err, "can not compile search query")
+	}
+
+	return Matcher{regex: regex}, nil
+}
+
+// Match returns the snippet of the first match in the given value and if the
+// value matched at all.
+func (matcher Matcher) Match(value string) (Snippet, bool) {
+	location := matcher.regex.FindStringIndex(value)
+	if location == nil {
+		return Snippet{}, false
+	}
+
+	start := snippetStart(value, location[0])
+	end := snippetEnd(value, location[1])
+
+	snippet := Snippet{
+		Before: cleanSnippet(value[start:location[0]]),
+		Match:  cleanSnippet(value[location[0]:location[1]]),
+		After:  cleanSnippet(value[location[1]:end]),
+	}
+
+	if start != 0 {
+		snippet.Before = "..." + snippet.Before
+	}
+
+	if end != len(value) {
+		snippet.After = snippet.After + "..."
+	}
+
+	return snippet, true
+}
+
+// Projects searches the notes and the current state of the todos of all given
+// projects. The results are ordered by project and timestamp.
+func Projects(matcher Matcher, projects data.Projects) []Result {
+	var out []Result
+	for _, project := range projects.List() {
+		out = append(out, Project(matcher, project)...)
+	}
+
+	return out
+}
+
+// Project searches the notes and the current state of the todos of the given
+// project.
+func Project(matcher Matcher, project data.Project) []Result {
+	var out []Result
+
+	for _, note := range project.Notes() {
+		snippet, ok := matcher.Match(note.Value)
+		if ok {
+			out = append(out, Result{Project: project.Name, Entry: note, Snippet: snippet})
+		}
+	}
+
+	for _, todo := range project.Todos() {
+		snippet, ok := matcher.Match(todo.Value)
+		if ok {
+			out = append(out, Result{Project: project.Name, Entry: todo, Snippet: snippet})
+		}
+	}
+
+	sort.Stable(resultsByTimeStamp(out))
+
+	return out
+}
+
+func snippetStart(value string, index int) int {
+	start := index - SnippetContext
+	if start <= 0 {
+		return 0
+	}
+
+	// Do not cut utf8 characters in half.
+	for start < index && !isRuneStart(value[start]) {
+		start++
+	}
+
+	return start
+}
+
+func snippetEnd(value string, index int) int {
+	end := index + SnippetContext
+	if end >= len(value) {
+		return len(value)
+	}
+
+	for end > index && !isRuneStart(value[end]) {
+		end--
+	}
+
+	return end
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+func cleanSnippet(value string) string {
+	return strings.Replace(value, "\n", " ", -1)
+}
+
+type resultsByTimeStamp []Result
+
+func (results resultsByTimeStamp) Len() int {
+	return len(results)
+}
+
+func (results resultsByTimeStamp) Less(i, j int) bool {
+	return results[i].Entry.GetTimeStamp().Before(results[j].Entry.GetTimeStamp())
+}
+
+func (results resultsByTimeStamp) Swap(i, j int) {
+	results[i], results[j] = results[j], results[i]
+}
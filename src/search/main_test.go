@@ -0,0 +1,102 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func Test_MatcherSubstring(t *testing.T) {
+	matcher, err := NewMatcher(Query{Value: "a.b"})
+	if err != nil {
+		t.Fatal("can not create matcher: ", err)
+	}
+
+	got, ok := matcher.Match("xx a.b yy")
+	testhelper.CompareGotExpected(t, nil, ok, true)
+	testhelper.CompareGotExpected(t, nil, got, Snippet{Before: "xx ", Match: "a.b", After: " yy"})
+
+	_, ok = matcher.Match("xx acb yy")
+	testhelper.CompareGotExpected(t, nil, ok, false)
+}
+
+func Test_MatcherRegex(t *testing.T) {
+	matcher, err := NewMatcher(Query{Value: "a.b", Regex: true})
+	if err != nil {
+		t.Fatal("can not create matcher: ", err)
+	}
+
+	got, ok := matcher.Match("xx acb yy")
+	testhelper.CompareGotExpected(t, nil, ok, true)
+	testhelper.CompareGotExpected(t, nil, got.Match, "acb")
+}
+
+func Test_MatcherIgnoreCase(t *testing.T) {
+	matcher, err := NewMatcher(Query{Value: "NOTE", IgnoreCase: true})
+	if err != nil {
+		t.Fatal("can not create matcher: ", err)
+	}
+
+	got, ok := matcher.Match("a note\nin two lines")
+	testhelper.CompareGotExpected(t, nil, ok, true)
+	testhelper.CompareGotExpected(t, nil, got.String(), "a note in two lines")
+}
+
+func Test_MatcherSnippetContext(t *testing.T) {
+	matcher, err := NewMatcher(Query{Value: "needle"})
+	if err != nil {
+		t.Fatal("can not create matcher: ", err)
+	}
+
+	padding := strings.Repeat("x", SnippetContext*2)
+
+	got, ok := matcher.Match(padding + "needle" + padding)
+	testhelper.CompareGotExpected(t, nil, ok, true)
+
+	expected := Snippet{
+		Before: "..." + strings.Repeat("x", SnippetContext),
+		Match:  "needle",
+		After:  strings.Repeat("x", SnippetContext) + "...",
+	}
+	testhelper.CompareGotExpected(t, nil, got, expected)
+}
+
+func Test_NewMatcherInvalid(t *testing.T) {
+	_, err := NewMatcher(Query{Value: "(", Regex: true})
+	if err == nil {
+		t.Fatal("expected error for invalid regex")
+	}
+
+	_, err = NewMatcher(Query{})
+	if err == nil {
+		t.Fatal("expected error for empty query")
+	}
+}
+
+func Test_Projects(t *testing.T) {
+	projects := testhelper.GetTestProjects(2, 1, "A", "B")
+
+	project := testhelper.GetTestProject("C", 0, 0)
+	project.AddNote(testhelper.GetTestNote(0, "something else"))
+	projects.Add(project)
+
+	matcher, err := NewMatcher(Query{Value: "todo"})
+	if err != nil {
+		t.Fatal("can not create matcher: ", err)
+	}
+
+	got := Projects(matcher, projects)
+
+	var expected []Result
+	for _, suffix := range []string{"A", "B"} {
+		expected = append(expected, Result{
+			Project: data.ProjectName{"Test", "Project", suffix},
+			Entry:   testhelper.GetTestTodo(0, "todo todo todo"),
+			Snippet: Snippet{Match: "todo", After: " todo todo"},
+		})
+	}
+
+	testhelper.CompareGotExpected(t, nil, got, expected)
+}
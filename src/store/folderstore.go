@@ -1,12 +1,27 @@
 package store
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
 	"github.com/AlexanderThaller/dbfiles"
 	"github.com/AlexanderThaller/lablog/src/data"
 	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errgo"
 )
 
+// loadWorkers is the number of project files that will be read concurrently
+// when loading multiple projects.
+const loadWorkers = 16
+
+// MetaFolder is the folder inside the datadir that contains the files lablog
+// keeps next to the projects like the search index. It is never searched for
+// project files.
+const MetaFolder = ".lablog"
+
 func NewFolderStore(datadir string) (Store, error) {
 	return FolderStore{datadir}, nil
 }
@@ -25,8 +40,240 @@ func (store FolderStore) AddEntry(name data.ProjectName, entry data.Entry) error
 	return nil
 }
 
-func (store FolderStore) GetProjects() (data.Projects, error) {
-	return data.Projects{}, errgo.New("not implemented")
+// UpdateEntry replaces the old entry in the project with the updated entry.
+// The entry is identified by its type and timestamp.
+func (store FolderStore) UpdateEntry(name data.ProjectName, old, updated data.Entry) error {
+	return store.UpdateEntries(name, []data.Entry{old}, []data.Entry{updated})
+}
+
+// DeleteEntry removes the entry from the project. The entry is identified by
+// its type and timestamp.
+func (store FolderStore) DeleteEntry(name data.ProjectName, entry data.Entry) error {
+	return store.DeleteEntries(name, []data.Entry{entry})
+}
+
+// UpdateEntries replaces each of the old entries in the project with the
+// updated entry at the same position. Entries are identified by their type and
+// timestamp. The project file is rewritten atomically and only once for all
+// entries.
+func (store FolderStore) UpdateEntries(name data.ProjectName, old, updated []data.Entry) error {
+	if len(old) != len(updated) {
+		return errgo.New("need the same amount of old and updated entries")
+	}
+
+	values := make([][]string, len(updated))
+	for i, entry := range updated {
+		values[i] = entry.Values()
+	}
+
+	err := store.rewriteEntries(name, old, values)
+	if err != nil {
+		return errgo.Notef(err, "can not update entries")
+	}
+
+	return nil
+}
+
+// DeleteEntries removes the entries from the project. Entries are identified
+// by their type and timestamp. The project file is rewritten atomically and
+// only once for all entries.
+func (store FolderStore) DeleteEntries(name data.ProjectName, entries []data.Entry) error {
+	err := store.rewriteEntries(name, entries, make([][]string, len(entries)))
+	if err != nil {
+		return errgo.Notef(err, "can not delete entries")
+	}
+
+	return nil
+}
+
+// rewriteEntries replaces the values of the only entry with the same type and
+// timestamp as each of the given entries with the values at the same position.
+// If the values are nil the entry is removed.
+func (store FolderStore) rewriteEntries(name data.ProjectName, matches []data.Entry, values [][]string) error {
+	path := store.projectPath(name)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return errgo.Notef(err, "can not open project file")
+	}
+
+	records, err := store.driver().Read(file)
+	file.Close()
+	if err != nil {
+		return errgo.Notef(err, "can not read values from project file")
+	}
+
+	var out [][]string
+	found := make([]int, len(matches))
+	for _, record := range records {
+		entry, err := data.ParseEntry(record)
+		if err != nil {
+			return errgo.Notef(err, "can not parse entry from value")
+		}
+
+		match := matchingEntry(entry, matches)
+		if match == -1 {
+			out = append(out, record)
+			continue
+		}
+
+		found[match]++
+		if values[match] != nil {
+			out = append(out, values[match])
+		}
+	}
+
+	for i, match := range matches {
+		timestamp := match.GetTimeStamp().Format(data.TimeStampFormat)
+		switch found[i] {
+		case 0:
+			return errgo.New("no " + match.Type().String() + " with the timestamp " + timestamp)
+		case 1:
+		default:
+			return errgo.New("more than one " + match.Type().String() + " with the timestamp " + timestamp)
+		}
+	}
+
+	err = store.writeProjectFile(path, out)
+	if err != nil {
+		return errgo.Notef(err, "can not write project file")
+	}
+
+	return nil
+}
+
+// matchingEntry returns the index of the first of the matches with the same
+// type and timestamp as the entry or -1 if there is none.
+func matchingEntry(entry data.Entry, matches []data.Entry) int {
+	for i, match := range matches {
+		if entry.Type() == match.Type() && entry.GetTimeStamp().Equal(match.GetTimeStamp()) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// writeProjectFile writes the given records to a temporary file next to the
+// project file and renames it afterwards so the project file is never left
+// partially written.
+func (store FolderStore) writeProjectFile(path string, records [][]string) error {
+	mode := os.FileMode(0640)
+
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		mode = info.Mode()
+	case os.IsNotExist(err):
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return errgo.Notef(err, "can not create folder for project file")
+		}
+	default:
+		return errgo.Notef(err, "can not stat project file")
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return errgo.Notef(err, "can not create temporary file")
+	}
+
+	for _, record := range records {
+		err = store.driver().Write(file, record)
+		if err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		err = file.Chmod(mode)
+	}
+
+	if err == nil {
+		err = file.Sync()
+	}
+
+	file.Close()
+	if err != nil {
+		os.Remove(file.Name())
+		return errgo.Notef(err, "can not write temporary file")
+	}
+
+	err = os.Rename(file.Name(), path)
+	if err != nil {
+		os.Remove(file.Name())
+		return errgo.Notef(err, "can not replace project file")
+	}
+
+	return nil
+}
+
+// MoveProject moves the file of the project to the file of the project with
+// the new name. It will fail if there already is a project with the new name.
+// Folders that are empty after the move are removed.
+func (store FolderStore) MoveProject(from, to data.ProjectName) error {
+	frompath := store.projectPath(from)
+	topath := store.projectPath(to)
+
+	_, err := os.Stat(topath)
+	if err == nil {
+		return errgo.New("there already is a project with the name " + to.String())
+	}
+	if !os.IsNotExist(err) {
+		return errgo.Notef(err, "can not stat new project file")
+	}
+
+	err = os.MkdirAll(filepath.Dir(topath), 0755)
+	if err != nil {
+		return errgo.Notef(err, "can not create folder for new project file")
+	}
+
+	err = os.Rename(frompath, topath)
+	if err != nil {
+		return errgo.Notef(err, "can not move project file")
+	}
+
+	store.removeEmptyFolders(filepath.Dir(frompath))
+
+	return nil
+}
+
+// removeEmptyFolders removes the given folder and its parents up to the
+// datadir as long as they are empty.
+func (store FolderStore) removeEmptyFolders(folder string) {
+	datadir := filepath.Clean(store.datadir)
+
+	for folder = filepath.Clean(folder); folder != datadir; folder = filepath.Dir(folder) {
+		if !strings.HasPrefix(folder, datadir) {
+			return
+		}
+
+		// Remove fails for folders that are not empty.
+		err := os.Remove(folder)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// GetProjects walks the datadir once and loads the entries of all projects.
+// The project files are read concurrently while the datadir is walked. If
+// some files can not be read the projects that could be loaded are returned
+// together with a LoadErrors.
+func (store FolderStore) GetProjects(showarchive bool) (data.Projects, error) {
+	projects := data.NewProjects()
+
+	err := store.loadProjects(projects, func(load func(data.ProjectName)) error {
+		return store.walkKeys(func(key []string) {
+			if !showarchive && key[0] == data.ArchiveName {
+				return
+			}
+
+			load(data.ProjectName(key))
+		})
+	})
+
+	return projects, err
 }
 
 func (store FolderStore) PutProject(project data.Project) error {
@@ -40,11 +287,48 @@ func (store FolderStore) PutProject(project data.Project) error {
 	return nil
 }
 
+// ReplaceProject replaces all entries of the project with the entries of the
+// given project. The project file is created if it does not exist yet and is
+// rewritten atomically otherwise.
+func (store FolderStore) ReplaceProject(project data.Project) error {
+	var records [][]string
+	for _, entry := range project.Entries {
+		records = append(records, entry.Values())
+	}
+
+	err := store.writeProjectFile(store.projectPath(project.Name), records)
+	if err != nil {
+		return errgo.Notef(err, "can not write project file")
+	}
+
+	return nil
+}
+
+// DeleteProject removes the file of the project. Folders that are empty
+// afterwards are removed as well.
+func (store FolderStore) DeleteProject(name data.ProjectName) error {
+	path := store.projectPath(name)
+
+	err := os.Remove(path)
+	if err != nil {
+		return errgo.Notef(err, "can not remove project file")
+	}
+
+	store.removeEmptyFolders(filepath.Dir(path))
+
+	return nil
+}
+
 func (store FolderStore) GetProject(name data.ProjectName) (data.Project, error) {
-	db := store.db()
-	values, err := db.Get(name.Values()...)
+	file, err := os.Open(store.projectPath(name))
 	if err != nil {
-		return data.Project{}, errgo.Notef(err, "can not put entry into the database")
+		return data.Project{}, errgo.Notef(err, "can not open project file")
+	}
+	defer file.Close()
+
+	values, err := store.driver().Read(file)
+	if err != nil {
+		return data.Project{}, errgo.Notef(err, "can not read values from project file")
 	}
 
 	var entries []data.Entry
@@ -61,8 +345,7 @@ func (store FolderStore) GetProject(name data.ProjectName) (data.Project, error)
 }
 
 func (store FolderStore) ListProjects(showarchive bool) (data.Projects, error) {
-	db := store.db()
-	keys, err := db.Keys()
+	keys, err := store.keys()
 	if err != nil {
 		return data.Projects{}, errgo.Notef(err, "can not get keys from database")
 	}
@@ -74,7 +357,7 @@ func (store FolderStore) ListProjects(showarchive bool) (data.Projects, error) {
 			if len(key) > 0 {
 				log.Debug("Key: ", key[0])
 
-				if key[0] == ".archive" {
+				if key[0] == data.ArchiveName {
 					continue
 				}
 			}
@@ -87,22 +370,170 @@ func (store FolderStore) ListProjects(showarchive bool) (data.Projects, error) {
 	return out, nil
 }
 
+// PopulateProjects loads the entries for all given projects using a bounded
+// pool of workers. Errors for single projects are collected and returned as
+// LoadErrors after all other projects have been populated.
 func (store FolderStore) PopulateProjects(projects *data.Projects) error {
-	for _, project := range projects.List() {
-		filled, err := store.GetProject(project.Name)
+	list := projects.List()
+
+	return store.loadProjects(*projects, func(load func(data.ProjectName)) error {
+		for _, project := range list {
+			load(project.Name)
+		}
+
+		return nil
+	})
+}
+
+// loadProjects reads the projects passed to load by feed with a bounded pool
+// of workers and sets them in the given projects. Errors for single projects
+// are collected and returned as LoadErrors after all other projects have been
+// loaded. An error of feed is returned instead.
+func (store FolderStore) loadProjects(projects data.Projects, feed func(load func(data.ProjectName)) error) error {
+	names := make(chan data.ProjectName)
+	filled := make(chan data.Project)
+	failed := make(chan LoadError)
+
+	workers := new(sync.WaitGroup)
+	for i := 0; i < loadWorkers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+
+			for name := range names {
+				project, err := store.GetProject(name)
+				if err != nil {
+					failed <- LoadError{Project: name, Err: err}
+					continue
+				}
+
+				filled <- project
+			}
+		}()
+	}
+
+	var feedErr error
+	go func() {
+		feedErr = feed(func(name data.ProjectName) {
+			names <- name
+		})
+		close(names)
+
+		workers.Wait()
+		close(filled)
+		close(failed)
+	}()
+
+	var errs LoadErrors
+	for filled != nil || failed != nil {
+		select {
+		case project, ok := <-filled:
+			if !ok {
+				filled = nil
+				continue
+			}
+
+			projects.Set(project)
+		case err, ok := <-failed:
+			if !ok {
+				failed = nil
+				continue
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	if feedErr != nil {
+		return errgo.Notef(feedErr, "can not get projects to load")
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// keys returns the keys of all project files in the datadir.
+func (store FolderStore) keys() ([][]string, error) {
+	var keys [][]string
+	err := store.walkKeys(func(key []string) {
+		keys = append(keys, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// walkKeys walks the datadir and calls fn with the key of every project file
+// as soon as it is found. The git and search index folders and files that do
+// not belong to the database are skipped.
+func (store FolderStore) walkKeys(fn func(key []string)) error {
+	_, err := os.Stat(store.datadir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	extention := "." + store.driver().Extention()
+
+	walkFn := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return errgo.Notef(err, "can not walk path "+path)
+		}
+
+		if info.IsDir() {
+			if info.Name() == ".git" || info.Name() == MetaFolder {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if filepath.Ext(path) != extention {
+			return nil
+		}
+
+		relpath, err := filepath.Rel(store.datadir, path)
 		if err != nil {
-			return errgo.Notef(err, "can not get project: "+project.Name.String())
+			return errgo.Notef(err, "can not get relative path")
 		}
 
-		projects.Set(filled)
+		key := strings.TrimSuffix(relpath, extention)
+		fn(strings.Split(key, string(os.PathSeparator)))
+
+		return nil
+	}
+
+	err = filepath.Walk(store.datadir, walkFn)
+	if err != nil {
+		return errgo.Notef(err, "can not walk through datadir")
 	}
 
 	return nil
 }
 
+func (store FolderStore) projectPath(name data.ProjectName) string {
+	return ProjectPath(store.datadir, name)
+}
+
+// ProjectPath returns the path of the file the folder store uses to save the
+// entries of the given project.
+func ProjectPath(datadir string, name data.ProjectName) string {
+	return filepath.Join(datadir, filepath.Join(name.Values()...)) +
+		"." + dbfiles.CSV{}.Extention()
+}
+
+func (store FolderStore) driver() dbfiles.Driver {
+	return dbfiles.CSV{}
+}
+
 func (store FolderStore) db() *dbfiles.DBFiles {
 	db := dbfiles.New()
 	db.BaseDir = store.datadir
+	db.Driver = store.driver()
 
 	return db
 }
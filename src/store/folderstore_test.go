@@ -2,6 +2,8 @@ package store
 
 import (
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/AlexanderThaller/lablog/src/data"
@@ -76,3 +78,340 @@ func Test_ListProjects(t *testing.T) {
 	got, err := store.ListProjects(false)
 	testhelper.CompareGotExpected(t, err, got.List(), expected)
 }
+
+func Test_GetProjects(t *testing.T) {
+	store, err := tmp_folderstore()
+	if err != nil {
+		t.Fatal("can not get tmp folderstore: ", err)
+	}
+
+	expected := testhelper.GetTestProjects(2, 2, "A", "B", "C", "D")
+	for _, project := range expected.List() {
+		err = store.PutProject(project)
+		if err != nil {
+			t.Fatal("can not put test project into store", err)
+		}
+	}
+
+	got, err := store.GetProjects(false)
+	if err != nil {
+		t.Fatal("can not get projects from store", err)
+	}
+
+	testhelper.CompareGotExpected(t, err, got.List(), expected.List())
+}
+
+func Test_GetProjectsSkipArchive(t *testing.T) {
+	store, err := tmp_folderstore()
+	if err != nil {
+		t.Fatal("can not get tmp folderstore: ", err)
+	}
+
+	project := testhelper.GetTestProject("A", 1, 1)
+	err = store.PutProject(project)
+	if err != nil {
+		t.Fatal("can not put test project into store", err)
+	}
+
+	archived := testhelper.GetTestProject("B", 1, 1)
+	archived.Name = append(data.ProjectName{".archive"}, archived.Name...)
+	err = store.PutProject(archived)
+	if err != nil {
+		t.Fatal("can not put archived test project into store", err)
+	}
+
+	got, err := store.GetProjects(false)
+	if err != nil {
+		t.Fatal("can not get projects from store", err)
+	}
+
+	testhelper.CompareGotExpected(t, err, got.List(), []data.Project{project})
+}
+
+func Test_GetProjectsSkipMetaFolder(t *testing.T) {
+	store, err := tmp_folderstore()
+	if err != nil {
+		t.Fatal("can not get tmp folderstore: ", err)
+	}
+
+	project := testhelper.GetTestProject("A", 1, 1)
+	err = store.PutProject(project)
+	if err != nil {
+		t.Fatal("can not put test project into store", err)
+	}
+
+	// Files in the meta folder are never projects even if they look like one.
+	folder := filepath.Join(store.(FolderStore).datadir, MetaFolder)
+	err = os.MkdirAll(folder, 0755)
+	if err != nil {
+		t.Fatal("can not create meta folder: ", err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(folder, "B.csv"), nil, 0644)
+	if err != nil {
+		t.Fatal("can not write file into meta folder: ", err)
+	}
+
+	got, err := store.GetProjects(true)
+	testhelper.CompareGotExpected(t, err, got.List(), []data.Project{project})
+}
+
+func Test_GetProjectsErrors(t *testing.T) {
+	store, err := tmp_folderstore()
+	if err != nil {
+		t.Fatal("can not get tmp folderstore: ", err)
+	}
+
+	project := testhelper.GetTestProject("A", 1, 1)
+	err = store.PutProject(project)
+	if err != nil {
+		t.Fatal("can not put test project into store", err)
+	}
+
+	broken := data.ProjectName{"Broken"}
+	err = ioutil.WriteFile(store.(FolderStore).projectPath(broken), []byte("garbage\n"), 0644)
+	if err != nil {
+		t.Fatal("can not write broken project file: ", err)
+	}
+
+	got, err := store.GetProjects(false)
+	errs, ok := err.(LoadErrors)
+	if !ok {
+		t.Fatal("expected load errors but got: ", err)
+	}
+
+	testhelper.CompareGotExpected(t, nil, len(errs), 1)
+	testhelper.CompareGotExpected(t, nil, errs[0].Project, broken)
+	testhelper.CompareGotExpected(t, nil, got.List(), []data.Project{project})
+}
+
+func Test_PopulateProjectsErrors(t *testing.T) {
+	store, err := tmp_folderstore()
+	if err != nil {
+		t.Fatal("can not get tmp folderstore: ", err)
+	}
+
+	project := testhelper.GetTestProject("A", 1, 1)
+	err = store.PutProject(project)
+	if err != nil {
+		t.Fatal("can not put test project into store", err)
+	}
+
+	missing := data.Project{Name: data.ProjectName{"Test", "Project", "Missing"}}
+
+	projects := data.NewProjects()
+	projects.Add(data.Project{Name: project.Name})
+	projects.Add(missing)
+
+	err = store.PopulateProjects(&projects)
+	errs, ok := err.(LoadErrors)
+	if !ok {
+		t.Fatal("expected load errors but got: ", err)
+	}
+
+	testhelper.CompareGotExpected(t, nil, len(errs), 1)
+	testhelper.CompareGotExpected(t, nil, errs[0].Project, missing.Name)
+	testhelper.CompareGotExpected(t, nil, projects.List(), []data.Project{project, missing})
+}
+
+func Test_UpdateEntry(t *testing.T) {
+	store, err := tmp_folderstore()
+	if err != nil {
+		t.Fatal("can not get tmp folderstore: ", err)
+	}
+
+	project := testhelper.GetTestProject("A", 2, 1)
+	err = store.PutProject(project)
+	if err != nil {
+		t.Fatal("can not put test project into store", err)
+	}
+
+	note := testhelper.GetTestNote(1, "changed note")
+	err = store.UpdateEntry(project.Name, project.Entries[1], note)
+	if err != nil {
+		t.Fatal("can not update entry", err)
+	}
+
+	project.Entries[1] = note
+
+	got, err := store.GetProject(project.Name)
+	testhelper.CompareGotExpected(t, err, got, project)
+}
+
+func Test_DeleteEntry(t *testing.T) {
+	store, err := tmp_folderstore()
+	if err != nil {
+		t.Fatal("can not get tmp folderstore: ", err)
+	}
+
+	project := testhelper.GetTestProject("A", 2, 1)
+	err = store.PutProject(project)
+	if err != nil {
+		t.Fatal("can not put test project into store", err)
+	}
+
+	err = store.DeleteEntry(project.Name, project.Entries[0])
+	if err != nil {
+		t.Fatal("can not delete entry", err)
+	}
+
+	project.Entries = project.Entries[1:]
+
+	got, err := store.GetProject(project.Name)
+	testhelper.CompareGotExpected(t, err, got, project)
+}
+
+func Test_DeleteEntryMissing(t *testing.T) {
+	store, err := tmp_folderstore()
+	if err != nil {
+		t.Fatal("can not get tmp folderstore: ", err)
+	}
+
+	project := testhelper.GetTestProject("A", 1, 0)
+	err = store.PutProject(project)
+	if err != nil {
+		t.Fatal("can not put test project into store", err)
+	}
+
+	err = store.DeleteEntry(project.Name, testhelper.GetTestNote(5, "missing"))
+	if err == nil {
+		t.Fatal("expected error when deleting missing entry")
+	}
+}
+
+func Test_UpdateEntriesMultiple(t *testing.T) {
+	store, err := tmp_folderstore()
+	if err != nil {
+		t.Fatal("can not get tmp folderstore: ", err)
+	}
+
+	project := testhelper.GetTestProject("A", 2, 1)
+	err = store.PutProject(project)
+	if err != nil {
+		t.Fatal("can not put test project into store", err)
+	}
+
+	first := testhelper.GetTestNote(0, "changed note")
+	second := testhelper.GetTestNote(1, "changed note")
+	err = store.UpdateEntries(project.Name, project.Entries[:2], []data.Entry{first, second})
+	if err != nil {
+		t.Fatal("can not update entries", err)
+	}
+
+	project.Entries[0] = first
+	project.Entries[1] = second
+
+	got, err := store.GetProject(project.Name)
+	testhelper.CompareGotExpected(t, err, got, project)
+}
+
+func Test_DeleteEntriesPartlyMissing(t *testing.T) {
+	store, err := tmp_folderstore()
+	if err != nil {
+		t.Fatal("can not get tmp folderstore: ", err)
+	}
+
+	project := testhelper.GetTestProject("A", 2, 0)
+	err = store.PutProject(project)
+	if err != nil {
+		t.Fatal("can not put test project into store", err)
+	}
+
+	err = store.DeleteEntries(project.Name, []data.Entry{project.Entries[0], testhelper.GetTestNote(5, "missing")})
+	if err == nil {
+		t.Fatal("expected error when deleting missing entry")
+	}
+
+	// Nothing is deleted if one of the entries is missing.
+	got, err := store.GetProject(project.Name)
+	testhelper.CompareGotExpected(t, err, got, project)
+}
+
+func Test_MoveProject(t *testing.T) {
+	store, err := tmp_folderstore()
+	if err != nil {
+		t.Fatal("can not get tmp folderstore: ", err)
+	}
+
+	project := testhelper.GetTestProject("A", 1, 1)
+	err = store.PutProject(project)
+	if err != nil {
+		t.Fatal("can not put test project into store", err)
+	}
+
+	archived := project.Name.Archived()
+	err = store.MoveProject(project.Name, archived)
+	if err != nil {
+		t.Fatal("can not move project", err)
+	}
+
+	got, err := store.ListProjects(true)
+	testhelper.CompareGotExpected(t, err, got.List(), []data.Project{data.Project{Name: archived}})
+
+	moved, err := store.GetProject(archived)
+	testhelper.CompareGotExpected(t, err, moved.Entries, project.Entries)
+}
+
+func Test_MoveProjectExisting(t *testing.T) {
+	store, err := tmp_folderstore()
+	if err != nil {
+		t.Fatal("can not get tmp folderstore: ", err)
+	}
+
+	projects := testhelper.GetTestProjects(1, 1, "A", "B")
+	for _, project := range projects.List() {
+		err = store.PutProject(project)
+		if err != nil {
+			t.Fatal("can not put test project into store", err)
+		}
+	}
+
+	list := projects.List()
+	err = store.MoveProject(list[0].Name, list[1].Name)
+	if err == nil {
+		t.Fatal("expected error when moving onto an existing project")
+	}
+}
+
+func Test_ReplaceProject(t *testing.T) {
+	store, err := tmp_folderstore()
+	if err != nil {
+		t.Fatal("can not get tmp folderstore: ", err)
+	}
+
+	project := testhelper.GetTestProject("A", 2, 2)
+	err = store.PutProject(project)
+	if err != nil {
+		t.Fatal("can not put test project into store", err)
+	}
+
+	replaced := testhelper.GetTestProject("A", 1, 0)
+	err = store.ReplaceProject(replaced)
+	if err != nil {
+		t.Fatal("can not replace project", err)
+	}
+
+	got, err := store.GetProject(project.Name)
+	testhelper.CompareGotExpected(t, err, got, replaced)
+}
+
+func Test_DeleteProject(t *testing.T) {
+	store, err := tmp_folderstore()
+	if err != nil {
+		t.Fatal("can not get tmp folderstore: ", err)
+	}
+
+	project := testhelper.GetTestProject("A", 1, 1)
+	err = store.ReplaceProject(project)
+	if err != nil {
+		t.Fatal("can not replace project", err)
+	}
+
+	err = store.DeleteProject(project.Name)
+	if err != nil {
+		t.Fatal("can not delete project", err)
+	}
+
+	got, err := store.ListProjects(true)
+	testhelper.CompareGotExpected(t, err, len(got.List()), 0)
+}
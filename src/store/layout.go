@@ -0,0 +1,60 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errgo"
+)
+
+const (
+	// LayoutFile is the file in the datadir that contains the version of the
+	// layout of the project files in the datadir.
+	LayoutFile = ".lablog-layout"
+	// LayoutVersion is the layout version of the folder store.
+	LayoutVersion = 1
+)
+
+// Layout returns the layout version of the datadir. A datadir without a
+// layout file was not initialized and has the version 0. Datadirs that were
+// used before the layout file existed have no layout file but contain project
+// files in the first layout, so they have the version 1.
+func Layout(datadir string) (int, error) {
+	content, err := ioutil.ReadFile(filepath.Join(datadir, LayoutFile))
+	if os.IsNotExist(err) {
+		keys, err := FolderStore{datadir}.keys()
+		if err != nil {
+			return 0, errgo.Notef(err, "can not look for project files")
+		}
+
+		if len(keys) != 0 {
+			return 1, nil
+		}
+
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errgo.Notef(err, "can not read layout file")
+	}
+
+	version, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return 0, errgo.Notef(err, "can not parse layout version")
+	}
+
+	return version, nil
+}
+
+// WriteLayout writes the current layout version to the datadir.
+func WriteLayout(datadir string) error {
+	err := ioutil.WriteFile(filepath.Join(datadir, LayoutFile),
+		[]byte(strconv.Itoa(LayoutVersion)+"\n"), 0644)
+	if err != nil {
+		return errgo.Notef(err, "can not write layout file")
+	}
+
+	return nil
+}
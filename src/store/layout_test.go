@@ -0,0 +1,52 @@
+package store
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func Test_Layout(t *testing.T) {
+	datadir, err := ioutil.TempDir("", "layout_test")
+	if err != nil {
+		t.Fatal("can not open tmpdir: ", err)
+	}
+
+	version, err := Layout(datadir)
+	testhelper.CompareGotExpected(t, err, version, 0)
+
+	err = WriteLayout(datadir)
+	if err != nil {
+		t.Fatal("can not write layout: ", err)
+	}
+
+	version, err = Layout(datadir)
+	testhelper.CompareGotExpected(t, err, version, LayoutVersion)
+
+	err = ioutil.WriteFile(filepath.Join(datadir, LayoutFile), []byte("invalid"), 0644)
+	if err != nil {
+		t.Fatal("can not write layout file: ", err)
+	}
+
+	_, err = Layout(datadir)
+	if err == nil {
+		t.Fatal("expected an error for an invalid layout file")
+	}
+}
+
+func Test_LayoutWithoutFile(t *testing.T) {
+	datadir, err := ioutil.TempDir("", "layout_test")
+	if err != nil {
+		t.Fatal("can not open tmpdir: ", err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(datadir, "Test.csv"), []byte{}, 0644)
+	if err != nil {
+		t.Fatal("can not write project file: ", err)
+	}
+
+	version, err := Layout(datadir)
+	testhelper.CompareGotExpected(t, err, version, 1)
+}
@@ -1,12 +1,45 @@
 package store
 
-import "github.com/AlexanderThaller/lablog/src/data"
+import (
+	"strings"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+)
 
 type Store interface {
 	AddEntry(data.ProjectName, data.Entry) error
+	UpdateEntry(data.ProjectName, data.Entry, data.Entry) error
+	DeleteEntry(data.ProjectName, data.Entry) error
+	UpdateEntries(data.ProjectName, []data.Entry, []data.Entry) error
+	DeleteEntries(data.ProjectName, []data.Entry) error
 	GetProject(data.ProjectName) (data.Project, error)
-	GetProjects() (data.Projects, error)
+	GetProjects(bool) (data.Projects, error)
 	ListProjects(bool) (data.Projects, error)
 	PutProject(data.Project) error
+	ReplaceProject(data.Project) error
+	DeleteProject(data.ProjectName) error
+	MoveProject(data.ProjectName, data.ProjectName) error
 	PopulateProjects(*data.Projects) error
 }
+
+// LoadError is the error that occured while loading a single project.
+type LoadError struct {
+	Project data.ProjectName
+	Err     error
+}
+
+func (err LoadError) Error() string {
+	return err.Project.String() + ": " + err.Err.Error()
+}
+
+// LoadErrors collects the errors of all projects that could not be loaded.
+type LoadErrors []LoadError
+
+func (errs LoadErrors) Error() string {
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+
+	return "can not load projects: " + strings.Join(messages, "; ")
+}
@@ -0,0 +1,179 @@
+package store
+
+import (
+	"sort"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errgo"
+)
+
+// MoveProjects will move the project with the given name to the new name. If
+// recursive is true all subprojects are moved as well and keep their position
+// below the project. All new names are checked before anything is moved. It
+// returns the old and new name of every moved project.
+func MoveProjects(store Store, from, to data.ProjectName, recursive bool) ([][2]data.ProjectName, error) {
+	projects, err := store.ListProjects(true)
+	if err != nil {
+		return nil, errgo.Notef(err, "can not get list of projects")
+	}
+
+	var moves [][2]data.ProjectName
+	for _, project := range projects.List() {
+		if !project.Name.HasPrefix(from) {
+			continue
+		}
+
+		if len(project.Name) != len(from) && !recursive {
+			continue
+		}
+
+		name := append(append(data.ProjectName{}, to...), project.Name[len(from):]...)
+		moves = append(moves, [2]data.ProjectName{project.Name, name})
+	}
+
+	if len(moves) == 0 {
+		return nil, errgo.New("no project with the name " + from.String())
+	}
+
+	if recursive && (to.HasPrefix(from) || from.HasPrefix(to)) {
+		return nil, errgo.New("can not move " + from.String() + " into its own subtree " + to.String())
+	}
+
+	// Check all new names before moving anything so a conflict does not leave
+	// the projects half moved.
+	existing := make(map[string]struct{})
+	for _, project := range projects.List() {
+		existing[project.Name.String()] = struct{}{}
+	}
+
+	for _, move := range moves {
+		if _, ok := existing[move[1].String()]; ok {
+			return nil, errgo.New("there already is a project with the name " + move[1].String())
+		}
+	}
+
+	for _, move := range moves {
+		log.Debug("Moving ", move[0], " to ", move[1])
+
+		err := store.MoveProject(move[0], move[1])
+		if err != nil {
+			return nil, errgo.Notef(err, "can not move project "+move[0].String())
+		}
+	}
+
+	return moves, nil
+}
+
+// MergeProjects will merge the entries of the project and all of its
+// subprojects into the project with the new name and its subprojects. The
+// entries are sorted by their timestamp and identical entries are only kept
+// once. All merged projects are written before the source projects are
+// removed so a failed merge never loses entries and can be run again.
+func MergeProjects(store Store, from, to data.ProjectName) error {
+	if to.HasPrefix(from) {
+		return errgo.New("can not merge " + from.String() + " into its own subtree " + to.String())
+	}
+
+	projects, err := store.ListProjects(true)
+	if err != nil {
+		return errgo.Notef(err, "can not get list of projects")
+	}
+
+	existing := make(map[string]struct{})
+	for _, project := range projects.List() {
+		existing[project.Name.String()] = struct{}{}
+	}
+
+	// Read all sources before anything is written. Merging a project into its
+	// parent can make a source the target of one of its own subprojects.
+	sources := make(map[string]data.Project)
+	for _, project := range projects.List() {
+		if !project.Name.HasPrefix(from) {
+			continue
+		}
+
+		source, err := store.GetProject(project.Name)
+		if err != nil {
+			return errgo.Notef(err, "can not get project "+project.Name.String())
+		}
+
+		sources[source.Name.String()] = source
+	}
+
+	if len(sources) == 0 {
+		return errgo.New("no project with the name " + from.String())
+	}
+
+	targets := make(map[string]data.Project)
+	for _, source := range sources {
+		name := append(append(data.ProjectName{}, to...), source.Name[len(from):]...)
+
+		target, ok := targets[name.String()]
+		if !ok {
+			target = data.Project{Name: name}
+
+			// The entries of a target that is also a source are moved to its own
+			// target so only the entries merged into it are kept.
+			_, isSource := sources[name.String()]
+			if _, ok := existing[name.String()]; ok && !isSource {
+				target, err = store.GetProject(name)
+				if err != nil {
+					return errgo.Notef(err, "can not get project "+name.String())
+				}
+			}
+		}
+
+		log.Debug("Merging ", source.Name, " into ", target.Name)
+		target.Entries = data.MergeEntries(target.Entries, source.Entries)
+		targets[name.String()] = target
+	}
+
+	// Shorter names are written first as the entries of a target that is also
+	// a source are always merged into a target with a shorter name.
+	var ordered []data.Project
+	for _, target := range targets {
+		ordered = append(ordered, target)
+	}
+	sort.Sort(projectsByNameLength(ordered))
+
+	for _, target := range ordered {
+		err = store.ReplaceProject(target)
+		if err != nil {
+			return errgo.Notef(err, "can not write merged project "+target.Name.String()+
+				", no source project was removed")
+		}
+	}
+
+	for name, source := range sources {
+		if _, ok := targets[name]; ok {
+			continue
+		}
+
+		err = store.DeleteProject(source.Name)
+		if err != nil {
+			return errgo.Notef(err, "all entries were merged but can not remove source project "+
+				source.Name.String()+", run the merge again to remove the remaining sources")
+		}
+	}
+
+	return nil
+}
+
+type projectsByNameLength []data.Project
+
+func (projects projectsByNameLength) Len() int {
+	return len(projects)
+}
+
+func (projects projectsByNameLength) Swap(i, j int) {
+	projects[i], projects[j] = projects[j], projects[i]
+}
+
+func (projects projectsByNameLength) Less(i, j int) bool {
+	if len(projects[i].Name) != len(projects[j].Name) {
+		return len(projects[i].Name) < len(projects[j].Name)
+	}
+
+	return projects[i].Name.String() < projects[j].Name.String()
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func Test_MoveProjects(t *testing.T) {
+	store, err := tmp_folderstore()
+	if err != nil {
+		t.Fatal("can not get tmp folderstore: ", err)
+	}
+
+	for _, name := range []data.ProjectName{{"Test"}, {"Test", "Sub"}, {"Other"}} {
+		err = store.AddEntry(name, testhelper.GetTestNote(0, "note"))
+		if err != nil {
+			t.Fatal("can not add entry: ", err)
+		}
+	}
+
+	_, err = MoveProjects(store, data.ProjectName{"Test"}, data.ProjectName{"Other"}, true)
+	if err == nil {
+		t.Fatal("expected an error when moving onto an existing project")
+	}
+
+	moves, err := MoveProjects(store, data.ProjectName{"Test"}, data.ProjectName{"Moved"}, true)
+	testhelper.CompareGotExpected(t, err, moves, [][2]data.ProjectName{
+		{{"Test"}, {"Moved"}},
+		{{"Test", "Sub"}, {"Moved", "Sub"}},
+	})
+
+	projects, err := store.ListProjects(true)
+	testhelper.CompareGotExpected(t, err, projects.List(), []data.Project{
+		{Name: data.ProjectName{"Moved"}},
+		{Name: data.ProjectName{"Moved", "Sub"}},
+		{Name: data.ProjectName{"Other"}},
+	})
+}
+
+func Test_MergeProjectsIntoParent(t *testing.T) {
+	store, err := tmp_folderstore()
+	if err != nil {
+		t.Fatal("can not get tmp folderstore: ", err)
+	}
+
+	entries := map[string]data.Note{
+		"work":                 testhelper.GetTestNote(0, "work"),
+		"work.clientA":         testhelper.GetTestNote(1, "clientA"),
+		"work.clientA.clientA": testhelper.GetTestNote(2, "nested"),
+		"work.clientA.other":   testhelper.GetTestNote(3, "other"),
+	}
+
+	for name, note := range entries {
+		project, err := data.ParseProjectName(name)
+		if err != nil {
+			t.Fatal("can not parse project name: ", err)
+		}
+
+		err = store.AddEntry(project, note)
+		if err != nil {
+			t.Fatal("can not add entry: ", err)
+		}
+	}
+
+	err = MergeProjects(store, data.ProjectName{"work", "clientA"}, data.ProjectName{"work"})
+	if err != nil {
+		t.Fatal("can not merge projects: ", err)
+	}
+
+	expected := map[string][]data.Note{
+		"work":         {entries["work"], entries["work.clientA"]},
+		"work.clientA": {entries["work.clientA.clientA"]},
+		"work.other":   {entries["work.clientA.other"]},
+	}
+
+	projects, err := store.ListProjects(true)
+	if err != nil {
+		t.Fatal("can not list projects: ", err)
+	}
+	testhelper.CompareGotExpected(t, nil, len(projects.List()), len(expected))
+
+	for name, notes := range expected {
+		project, err := data.ParseProjectName(name)
+		if err != nil {
+			t.Fatal("can not parse project name: ", err)
+		}
+
+		got, err := store.GetProject(project)
+		testhelper.CompareGotExpected(t, err, got.Notes(), notes)
+	}
+}
+
+func Test_MergeProjectsIntoSubtree(t *testing.T) {
+	store, err := tmp_folderstore()
+	if err != nil {
+		t.Fatal("can not get tmp folderstore: ", err)
+	}
+
+	err = store.AddEntry(data.ProjectName{"work"}, testhelper.GetTestNote(0, "work"))
+	if err != nil {
+		t.Fatal("can not add entry: ", err)
+	}
+
+	err = MergeProjects(store, data.ProjectName{"work"}, data.ProjectName{"work", "clientA"})
+	if err == nil {
+		t.Fatal("expected an error when merging a project into its own subtree")
+	}
+}
@@ -30,11 +30,7 @@ func GetTestProject(suffix string, notes, todos int) data.Project {
 	}
 
 	for i := 0; i != todos; i++ {
-		project.AddTodo(data.Todo{
-			TimeStamp: time.Date(2010+i, time.November, 10, 23, 0, 0, 0, time.UTC),
-			Value:     "todo todo todo",
-			Active:    true,
-		})
+		project.AddTodo(GetTestTodo(i, "todo todo todo"))
 	}
 
 	return project
@@ -47,6 +43,17 @@ func GetTestNote(increment int, value string) data.Note {
 	}
 }
 
+func GetTestTodo(increment int, value string) data.Todo {
+	timestamp := time.Date(2010+increment, time.November, 10, 23, 0, 0, 0, time.UTC)
+
+	return data.Todo{
+		TimeStamp: timestamp,
+		Value:     value,
+		Active:    true,
+		ID:        data.NewTodoID(timestamp, value),
+	}
+}
+
 func CompareGotExpected(t *testing.T, err error, got, expected interface{}) {
 	if reflect.DeepEqual(got, expected) {
 		return
@@ -0,0 +1,86 @@
+package vcs
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+// Backend records the changes of a datadir in a version control system.
+type Backend interface {
+	// Init creates the repository in the datadir if there is none.
+	Init() error
+	// Commit records all changes in the datadir with the message. Nothing is
+	// recorded if there are no changes.
+	Commit(message string) error
+	// Status returns the sorted paths of the files relative to the datadir
+	// that have changes which are not committed yet.
+	Status() ([]string, error)
+	// Log returns up to limit commits starting with the newest one. All
+	// commits are returned if the limit is 0.
+	Log(limit int) ([]LogEntry, error)
+	// Move records that the file at the path from was moved to the path to.
+	// The file has to be moved in the datadir already.
+	Move(from, to string) error
+}
+
+// LogEntry is a commit returned by Backend.Log.
+type LogEntry struct {
+	ID        string
+	TimeStamp time.Time
+	Message   string
+}
+
+// DefaultBackend is the name of the backend used if none is configured.
+const DefaultBackend = "git"
+
+// Backends contains the constructors of the backends that can be selected by
+// name.
+var Backends = map[string]func(datadir string) Backend{
+	"git":   NewGit,
+	"gogit": NewGoGit,
+	"none":  NewNoop,
+}
+
+// BackendName is the name of the backend returned by Open. It is set from the
+// config of the datadir.
+var BackendName = DefaultBackend
+
+// Open returns the backend with the name BackendName for the datadir.
+func Open(datadir string) (Backend, error) {
+	return OpenBackend(BackendName, datadir)
+}
+
+// OpenBackend returns the backend with the given name for the datadir.
+func OpenBackend(name, datadir string) (Backend, error) {
+	backend, ok := Backends[name]
+	if !ok {
+		var names []string
+		for name := range Backends {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return nil, errgo.New("the vcs backend " + name + " is not known, use one of " +
+			strings.Join(names, ", "))
+	}
+
+	return backend(datadir), nil
+}
+
+// NewNoop returns a backend that does not record anything.
+func NewNoop(datadir string) Backend {
+	return Noop{}
+}
+
+// Noop is a backend that does not record anything. It can be used for
+// datadirs that are not under version control.
+type Noop struct{}
+
+func (Noop) Init() error                       { return nil }
+func (Noop) Commit(message string) error       { return nil }
+func (Noop) Status() ([]string, error)         { return nil, nil }
+func (Noop) Log(limit int) ([]LogEntry, error) { return nil, nil }
+func (Noop) Move(from, to string) error        { return nil }
@@ -0,0 +1,47 @@
+package vcs
+
+import (
+	"strings"
+	"testing"
+
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func Test_OpenBackendUnknown(t *testing.T) {
+	_, err := OpenBackend("unknown", "")
+	if err == nil {
+		t.Fatal("expected an error for an unknown backend")
+	}
+
+	if !strings.Contains(err.Error(), "git, gogit, none") {
+		t.Fatal("expected the known backends in the error but got: ", err)
+	}
+}
+
+func Test_GitStatusLog(t *testing.T) {
+	datadir := tmpRepo(t)
+	backend := NewGit(datadir)
+
+	log, err := backend.Log(0)
+	testhelper.CompareGotExpected(t, err, log, []LogEntry(nil))
+
+	writeFile(t, datadir, "Test.csv")
+	writeFile(t, datadir, "README")
+
+	status, err := backend.Status()
+	testhelper.CompareGotExpected(t, err, status, []string{"README", "Test.csv"})
+
+	err = backend.Commit("first")
+	if err != nil {
+		t.Fatal("can not commit: ", err)
+	}
+
+	status, err = backend.Status()
+	testhelper.CompareGotExpected(t, err, status, []string(nil))
+
+	log, err = backend.Log(0)
+	if err != nil || len(log) != 1 {
+		t.Fatal("expected one commit but got: ", log, err)
+	}
+	testhelper.CompareGotExpected(t, nil, log[0].Message, "first")
+}
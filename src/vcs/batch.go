@@ -0,0 +1,92 @@
+package vcs
+
+import (
+	"sync"
+	"time"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errgo"
+)
+
+// MaxWaitDelays is the number of delays after which a Batcher flushes the
+// queue even if entries are still added.
+const MaxWaitDelays = 10
+
+// Batcher queues entries of a datadir and flushes the queue once no entry
+// was added for the delay so a burst of entries results in one commit. A
+// steady stream of entries is flushed after the maximum wait so the entries
+// are not left uncommitted. As the queue is kept in the datadir entries that
+// were not flushed when the process stopped are committed by the next Flush.
+type Batcher struct {
+	datadir string
+	delay   time.Duration
+	maxWait time.Duration
+
+	mutex sync.Mutex
+	timer *time.Timer
+	first time.Time
+}
+
+// NewBatcher returns a batcher for the datadir that flushes after the delay
+// or at the latest MaxWaitDelays delays after the first unflushed entry.
+func NewBatcher(datadir string, delay time.Duration) *Batcher {
+	return &Batcher{datadir: datadir, delay: delay, maxWait: MaxWaitDelays * delay}
+}
+
+// Add queues the entry which already was written to the datadir and restarts
+// the delay. The delay is shortened so the queue is flushed before the maximum
+// wait since the first unflushed entry is over.
+func (batcher *Batcher) Add(project data.ProjectName, entry data.Entry) error {
+	err := Enqueue(batcher.datadir, project, entry)
+	if err != nil {
+		return errgo.Notef(err, "can not queue entry")
+	}
+
+	batcher.mutex.Lock()
+	defer batcher.mutex.Unlock()
+
+	now := time.Now()
+	if batcher.first.IsZero() {
+		batcher.first = now
+	}
+
+	wait := batcher.delay
+	if remaining := batcher.first.Add(batcher.maxWait).Sub(now); remaining < wait {
+		wait = remaining
+	}
+
+	if batcher.timer == nil {
+		batcher.timer = time.AfterFunc(wait, batcher.flush)
+	} else {
+		batcher.timer.Reset(wait)
+	}
+
+	return nil
+}
+
+// Flush stops the delay and flushes the queue right away.
+func (batcher *Batcher) Flush() (string, error) {
+	batcher.mutex.Lock()
+	if batcher.timer != nil {
+		batcher.timer.Stop()
+	}
+	batcher.first = time.Time{}
+	batcher.mutex.Unlock()
+
+	return Flush(batcher.datadir)
+}
+
+func (batcher *Batcher) flush() {
+	batcher.mutex.Lock()
+	batcher.first = time.Time{}
+	batcher.mutex.Unlock()
+
+	message, err := Flush(batcher.datadir)
+	if err != nil {
+		log.Warning(errgo.Notef(err, "can not flush queued entries"))
+		return
+	}
+
+	log.Debug("Committed queued entries: ", message)
+}
@@ -0,0 +1,69 @@
+package vcs
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/juju/errgo"
+)
+
+// GitError is returned if a git command fails.
+type GitError struct {
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+func (err GitError) Error() string {
+	message := "git " + strings.Join(err.Args, " ") + ": " + err.Err.Error()
+
+	stderr := strings.TrimSpace(err.Stderr)
+	if stderr != "" {
+		message += ": " + stderr
+	}
+
+	return message
+}
+
+// LockedError is returned if the repository was still locked by another git
+// process after all retries.
+type LockedError struct {
+	Attempts int
+	Err      error
+}
+
+func (err LockedError) Error() string {
+	return "repository is locked by another git process after " +
+		strconv.Itoa(err.Attempts) + " attempts: " + err.Err.Error()
+}
+
+// LockError is returned if the lock file of lablog in the repository can not
+// be acquired.
+type LockError struct {
+	Path string
+	Err  error
+}
+
+func (err LockError) Error() string {
+	return "can not lock " + err.Path + ": " + err.Err.Error()
+}
+
+// IsLocked returns true if the error or one of the errors it wraps is a
+// LockedError or a LockError.
+func IsLocked(err error) bool {
+	for err != nil {
+		switch err.(type) {
+		case LockedError, LockError:
+			return true
+		}
+
+		wrapper, ok := err.(errgo.Wrapper)
+		if !ok {
+			return false
+		}
+
+		err = wrapper.Underlying()
+	}
+
+	return false
+}
@@ -0,0 +1,158 @@
+package vcs
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+// NewGit returns the backend that runs the git binary in the datadir.
+func NewGit(datadir string) Backend {
+	return Git{datadir: datadir}
+}
+
+// Git is the backend that runs the git binary. Git commands of lablog are
+// serialized in the process and between processes using the datadir.
+type Git struct {
+	datadir string
+}
+
+// Init runs git init if the datadir is not inside of a repository already.
+func (backend Git) Init() error {
+	_, err := git(backend.datadir, "rev-parse", "--git-dir")
+	if err == nil {
+		return nil
+	}
+
+	_, err = git(backend.datadir, "init", "-q")
+	if err != nil {
+		return errgo.Notef(err, "can not init repository")
+	}
+
+	return nil
+}
+
+// Commit adds and commits all changes. If there is nothing to commit because
+// the changes were already committed together with an earlier commit nothing
+// is done.
+func (backend Git) Commit(message string) error {
+	unlock, err := lock(backend.datadir)
+	if err != nil {
+		return errgo.Notef(err, "can not lock repository")
+	}
+	defer unlock()
+
+	err = gitAdd(backend.datadir, ".")
+	if err != nil {
+		return errgo.Notef(err, "can not add file to repository")
+	}
+
+	staged, err := gitStaged(backend.datadir)
+	if err != nil {
+		return errgo.Notef(err, "can not check for staged changes")
+	}
+
+	if !staged {
+		return nil
+	}
+
+	err = gitCommit(backend.datadir, message)
+	if err != nil {
+		return errgo.Notef(err, "can not commit file to repository")
+	}
+
+	return nil
+}
+
+func (backend Git) Status() ([]string, error) {
+	output, err := git(backend.datadir, "status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, errgo.Notef(err, "can not get status")
+	}
+
+	var paths []string
+	fields := strings.Split(output, "\x00")
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if len(field) < 4 {
+			continue
+		}
+
+		paths = append(paths, field[3:])
+
+		// Renames and copies are followed by the original path.
+		if field[0] == 'R' || field[0] == 'C' {
+			i++
+			if i < len(fields) {
+				paths = append(paths, fields[i])
+			}
+		}
+	}
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+func (backend Git) Log(limit int) ([]LogEntry, error) {
+	_, err := git(backend.datadir, "rev-parse", "--git-dir")
+	if err != nil {
+		return nil, errgo.Notef(err, "datadir is not in a git repository")
+	}
+
+	// A new repository has no commits yet.
+	_, err = git(backend.datadir, "rev-parse", "--verify", "-q", "HEAD")
+	if err != nil {
+		return nil, nil
+	}
+
+	args := []string{"log", "--format=%H%x00%ct%x00%B%x1e"}
+	if limit != 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+
+	output, err := git(backend.datadir, args...)
+	if err != nil {
+		return nil, errgo.Notef(err, "can not get log")
+	}
+
+	var entries []LogEntry
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		seconds, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, errgo.Notef(err, "can not parse commit time")
+		}
+
+		entries = append(entries, LogEntry{
+			ID:        fields[0],
+			TimeStamp: time.Unix(seconds, 0),
+			Message:   strings.TrimSpace(fields[2]),
+		})
+	}
+
+	return entries, nil
+}
+
+// Move stages the removal of the old and the addition of the new path so git
+// detects the rename.
+func (backend Git) Move(from, to string) error {
+	unlock, err := lock(backend.datadir)
+	if err != nil {
+		return errgo.Notef(err, "can not lock repository")
+	}
+	defer unlock()
+
+	_, err = git(backend.datadir, "add", "-A", "--", from, to)
+	if err != nil {
+		return errgo.Notef(err, "can not stage move")
+	}
+
+	return nil
+}
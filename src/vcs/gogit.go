@@ -0,0 +1,368 @@
+package vcs
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errgo"
+	"github.com/mitchellh/go-homedir"
+)
+
+// NewGoGit returns the backend that writes git repositories without the git
+// binary.
+func NewGoGit(datadir string) Backend {
+	return GoGit{datadir: datadir, gitdir: filepath.Join(datadir, ".git")}
+}
+
+// GoGit is a backend that reads and writes the git repository in the .git
+// folder of the datadir without running git so lablog works on hosts without
+// git. Commits are written as loose objects together with the index so git
+// can be used on the repository as well. Objects that git packed are read
+// from the pack files. The .gitignore files of the datadir only support simple patterns without
+// negation.
+type GoGit struct {
+	datadir string
+	gitdir  string
+}
+
+// Init creates an empty repository with the branch master.
+func (backend GoGit) Init() error {
+	_, err := os.Stat(backend.gitdir)
+	if err == nil {
+		return nil
+	}
+
+	for _, folder := range []string{"objects", "refs/heads", "refs/tags"} {
+		err := os.MkdirAll(filepath.Join(backend.gitdir, filepath.FromSlash(folder)), 0755)
+		if err != nil {
+			return errgo.Notef(err, "can not create git folder")
+		}
+	}
+
+	files := map[string]string{
+		"HEAD":   "ref: refs/heads/master\n",
+		"config": "[core]\n\trepositoryformatversion = 0\n\tfilemode = true\n\tbare = false\n",
+	}
+
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(backend.gitdir, name), []byte(content), 0644)
+		if err != nil {
+			return errgo.Notef(err, "can not write git file "+name)
+		}
+	}
+
+	return nil
+}
+
+// Commit writes the files of the datadir as a new commit on the current
+// branch and updates the index. Nothing is done if the files did not change
+// since the last commit.
+func (backend GoGit) Commit(message string) error {
+	mutex.Lock()
+	unlock, err := lockGitDir(backend.gitdir)
+	if err != nil {
+		return errgo.Notef(err, "can not lock repository")
+	}
+	defer unlock()
+
+	objects := newObjectStore(backend.gitdir)
+
+	tree, files, err := snapshot(objects, backend.datadir, true)
+	if err != nil {
+		return errgo.Notef(err, "can not write tree")
+	}
+
+	ref, parent, err := backend.head()
+	if err != nil {
+		return errgo.Notef(err, "can not get head")
+	}
+
+	content := "tree " + tree + "\n"
+	if parent != "" {
+		commit, err := objects.commit(parent)
+		if err != nil {
+			return errgo.Notef(err, "can not read parent commit")
+		}
+
+		if commit.Tree == tree {
+			return backend.writeIndex(files)
+		}
+
+		content += "parent " + parent + "\n"
+	}
+
+	now := time.Now()
+	content += "author " + backend.identity("AUTHOR") + " " + gitTime(now) + "\n"
+	content += "committer " + backend.identity("COMMITTER") + " " + gitTime(now) + "\n"
+	content += "\n" + strings.TrimRight(message, "\n") + "\n"
+
+	id, err := objects.write("commit", []byte(content))
+	if err != nil {
+		return errgo.Notef(err, "can not write commit")
+	}
+
+	path := filepath.Join(backend.gitdir, "HEAD")
+	if ref != "" {
+		path = filepath.Join(backend.gitdir, filepath.FromSlash(ref))
+	}
+
+	err = writeFileAtomic(path, []byte(id+"\n"))
+	if err != nil {
+		return errgo.Notef(err, "can not update head")
+	}
+
+	return backend.writeIndex(files)
+}
+
+func (backend GoGit) Status() ([]string, error) {
+	objects := newObjectStore(backend.gitdir)
+
+	_, files, err := snapshot(objects, backend.datadir, false)
+	if err != nil {
+		return nil, errgo.Notef(err, "can not read files")
+	}
+
+	committed := make(map[string]treeFile)
+
+	_, head, err := backend.head()
+	if err != nil {
+		return nil, errgo.Notef(err, "can not get head")
+	}
+
+	if head != "" {
+		commit, err := objects.commit(head)
+		if err != nil {
+			return nil, errgo.Notef(err, "can not read head commit")
+		}
+
+		err = objects.flatten(commit.Tree, "", committed)
+		if err != nil {
+			return nil, errgo.Notef(err, "can not read head tree")
+		}
+	}
+
+	var paths []string
+	for path, file := range files {
+		if old, ok := committed[path]; !ok || old.ID != file.ID || old.Mode != file.Mode {
+			paths = append(paths, path)
+		}
+	}
+
+	for path := range committed {
+		if _, ok := files[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+func (backend GoGit) Log(limit int) ([]LogEntry, error) {
+	objects := newObjectStore(backend.gitdir)
+
+	_, id, err := backend.head()
+	if err != nil {
+		return nil, errgo.Notef(err, "can not get head")
+	}
+
+	var entries []LogEntry
+	for id != "" && (limit == 0 || len(entries) < limit) {
+		commit, err := objects.commit(id)
+		if err != nil {
+			return nil, errgo.Notef(err, "can not read commit")
+		}
+
+		entries = append(entries, LogEntry{ID: id, TimeStamp: commit.TimeStamp,
+			Message: strings.TrimSpace(commit.Message)})
+
+		id = ""
+		if len(commit.Parents) != 0 {
+			id = commit.Parents[0]
+		}
+	}
+
+	return entries, nil
+}
+
+// Move does nothing as every commit contains all files of the datadir.
+func (backend GoGit) Move(from, to string) error {
+	return nil
+}
+
+// head returns the ref HEAD points to and the id of the commit of it. The id
+// is empty if there is no commit yet and the ref is empty if HEAD is detached.
+func (backend GoGit) head() (string, string, error) {
+	content, err := ioutil.ReadFile(filepath.Join(backend.gitdir, "HEAD"))
+	if err != nil {
+		return "", "", errgo.Notef(err, "can not read HEAD")
+	}
+
+	head := strings.TrimSpace(string(content))
+	if !strings.HasPrefix(head, "ref: ") {
+		return "", head, nil
+	}
+
+	ref := strings.TrimPrefix(head, "ref: ")
+
+	content, err = ioutil.ReadFile(filepath.Join(backend.gitdir, filepath.FromSlash(ref)))
+	if err == nil {
+		return ref, strings.TrimSpace(string(content)), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", "", errgo.Notef(err, "can not read ref")
+	}
+
+	id, err := backend.packedRef(ref)
+	if err != nil {
+		return "", "", errgo.Notef(err, "can not read packed refs")
+	}
+
+	return ref, id, nil
+}
+
+func (backend GoGit) packedRef(ref string) (string, error) {
+	file, err := os.Open(filepath.Join(backend.gitdir, "packed-refs"))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == ref {
+			return fields[0], nil
+		}
+	}
+
+	return "", scanner.Err()
+}
+
+// identity returns the name and email used for commits. Like git it uses the
+// environment variables GIT_AUTHOR_NAME, GIT_COMMITTER_EMAIL, etc. and the
+// user section of the config of the repository and of the user.
+func (backend GoGit) identity(role string) string {
+	name := os.Getenv("GIT_" + role + "_NAME")
+	email := os.Getenv("GIT_" + role + "_EMAIL")
+
+	paths := []string{filepath.Join(backend.gitdir, "config")}
+	if home, err := homedir.Dir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".gitconfig"))
+	}
+
+	for _, path := range paths {
+		user := gitConfigSection(path, "user")
+		if name == "" {
+			name = user["name"]
+		}
+		if email == "" {
+			email = user["email"]
+		}
+	}
+
+	if name == "" {
+		name = "lablog"
+	}
+
+	if email == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "localhost"
+		}
+
+		email = "lablog@" + hostname
+	}
+
+	return name + " <" + email + ">"
+}
+
+func (backend GoGit) writeIndex(files map[string]treeFile) error {
+	return writeIndex(filepath.Join(backend.gitdir, "index"), files)
+}
+
+// gitConfigSection returns the values of the section in the git config file.
+// Subsections and includes are not supported.
+func gitConfigSection(path, section string) map[string]string {
+	values := make(map[string]string)
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return values
+	}
+
+	var current string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "["):
+			current = strings.ToLower(strings.Trim(line, "[] "))
+		case current == section:
+			parts := strings.SplitN(line, "=", 2)
+			if len(parts) == 2 {
+				values[strings.ToLower(strings.TrimSpace(parts[0]))] = strings.Trim(strings.TrimSpace(parts[1]), `"`)
+			}
+		}
+	}
+
+	return values
+}
+
+// gitTime formats the time like git does in commits.
+func gitTime(t time.Time) string {
+	_, offset := t.Zone()
+
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+
+	return strconv.FormatInt(t.Unix(), 10) + " " + sign +
+		fmt.Sprintf("%02d%02d", offset/3600, offset%3600/60)
+}
+
+// writeFileAtomic writes the content to a temporary file next to the path and
+// renames it afterwards.
+func writeFileAtomic(path string, content []byte) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(content)
+	if err == nil {
+		err = file.Chmod(0644)
+	}
+
+	file.Close()
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	err = os.Rename(file.Name(), path)
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package vcs
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+// writeIndex writes the files as a version 2 git index so git sees the files
+// as staged. Only the modification time and size are recorded, git compares
+// the content of the files for the other stat fields.
+func writeIndex(path string, files map[string]treeFile) error {
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	index := new(bytes.Buffer)
+	index.WriteString("DIRC")
+	binary.Write(index, binary.BigEndian, uint32(2))
+	binary.Write(index, binary.BigEndian, uint32(len(paths)))
+
+	for _, name := range paths {
+		file := files[name]
+
+		id, err := hex.DecodeString(file.ID)
+		if err != nil {
+			return errgo.Notef(err, "can not decode id of "+name)
+		}
+
+		mode, err := indexMode(file.Mode)
+		if err != nil {
+			return errgo.Notef(err, "can not get mode of "+name)
+		}
+
+		var seconds, nanoseconds, size uint32
+		if file.Info != nil {
+			modtime := file.Info.ModTime()
+			seconds = uint32(modtime.Unix())
+			nanoseconds = uint32(modtime.Nanosecond())
+			size = uint32(file.Info.Size())
+		}
+
+		flags := len(name)
+		if flags > 0xfff {
+			flags = 0xfff
+		}
+
+		for _, value := range []uint32{
+			seconds, nanoseconds, // ctime
+			seconds, nanoseconds, // mtime
+			0, 0, // dev, ino
+			mode,
+			0, 0, // uid, gid
+			size,
+		} {
+			binary.Write(index, binary.BigEndian, value)
+		}
+		index.Write(id)
+		binary.Write(index, binary.BigEndian, uint16(flags))
+		index.WriteString(name)
+
+		// Entries are padded with one to eight null bytes to a multiple of
+		// eight bytes.
+		padding := 8 - (62+len(name))%8
+		index.Write(make([]byte, padding))
+	}
+
+	sum := sha1.Sum(index.Bytes())
+	index.Write(sum[:])
+
+	return writeIndexFile(path, index.Bytes())
+}
+
+func indexMode(mode string) (uint32, error) {
+	switch mode {
+	case modeFile:
+		return 0100644, nil
+	case modeExecutable:
+		return 0100755, nil
+	case modeSymlink:
+		return 0120000, nil
+	}
+
+	return 0, errgo.New("unknown mode " + mode)
+}
+
+// writeIndexFile writes the index through index.lock like git does so git
+// and lablog do not overwrite each others index.
+func writeIndexFile(path string, content []byte) error {
+	lockpath := path + ".lock"
+
+	var file *os.File
+	var err error
+	delay := RetryDelay
+	for attempt := 0; ; attempt++ {
+		file, err = os.OpenFile(lockpath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			break
+		}
+
+		if !os.IsExist(err) {
+			return errgo.Notef(err, "can not create index lock")
+		}
+
+		if attempt == Retries {
+			return LockedError{Attempts: attempt + 1, Err: err}
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+
+	_, err = file.Write(content)
+	file.Close()
+	if err != nil {
+		os.Remove(lockpath)
+		return errgo.Notef(err, "can not write index")
+	}
+
+	err = os.Rename(lockpath, path)
+	if err != nil {
+		os.Remove(lockpath)
+		return errgo.Notef(err, "can not replace index")
+	}
+
+	return nil
+}
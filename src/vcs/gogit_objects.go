@@ -0,0 +1,467 @@
+package vcs
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+const (
+	modeFile       = "100644"
+	modeExecutable = "100755"
+	modeSymlink    = "120000"
+	modeTree       = "40000"
+)
+
+// objectStore reads the loose and packed objects of a git repository and
+// writes new objects as loose objects.
+type objectStore struct {
+	gitdir string
+	packs  *packIndexes
+}
+
+func newObjectStore(gitdir string) objectStore {
+	return objectStore{gitdir: gitdir, packs: new(packIndexes)}
+}
+
+// treeFile is a file in a tree with its path relative to the root of the
+// tree.
+type treeFile struct {
+	ID   string
+	Mode string
+	Info os.FileInfo
+}
+
+type treeEntry struct {
+	Mode string
+	Name string
+	ID   string
+}
+
+type commitObject struct {
+	Tree      string
+	Parents   []string
+	TimeStamp time.Time
+	Message   string
+}
+
+// hashObject returns the id of the object and its content with the git
+// header.
+func hashObject(kind string, content []byte) (string, []byte) {
+	raw := append([]byte(kind+" "+strconv.Itoa(len(content))+"\x00"), content...)
+	sum := sha1.Sum(raw)
+
+	return hex.EncodeToString(sum[:]), raw
+}
+
+func (store objectStore) path(id string) string {
+	return filepath.Join(store.gitdir, "objects", id[:2], id[2:])
+}
+
+// write stores the object if it does not exist yet and returns its id.
+func (store objectStore) write(kind string, content []byte) (string, error) {
+	id, raw := hashObject(kind, content)
+
+	path := store.path(id)
+	if _, err := os.Stat(path); err == nil {
+		return id, nil
+	}
+
+	pack, _, err := store.packed(id)
+	if err != nil {
+		return "", errgo.Notef(err, "can not look for packed object "+id)
+	}
+	if pack != "" {
+		return id, nil
+	}
+
+	compressed := new(bytes.Buffer)
+	writer := zlib.NewWriter(compressed)
+	writer.Write(raw)
+	err = writer.Close()
+	if err != nil {
+		return "", errgo.Notef(err, "can not compress object")
+	}
+
+	err = writeFileAtomic(path, compressed.Bytes())
+	if err != nil {
+		return "", errgo.Notef(err, "can not write object "+id)
+	}
+
+	return id, nil
+}
+
+// read returns the kind and content of the object.
+func (store objectStore) read(id string) (string, []byte, error) {
+	if len(id) != 40 {
+		return "", nil, errgo.New("invalid object id " + id)
+	}
+
+	file, err := os.Open(store.path(id))
+	if os.IsNotExist(err) {
+		pack, offset, err := store.packed(id)
+		if err != nil {
+			return "", nil, errgo.Notef(err, "can not look for packed object "+id)
+		}
+		if pack == "" {
+			return "", nil, errgo.New("object " + id + " does not exist")
+		}
+
+		kind, content, err := store.readPacked(pack, offset)
+		if err != nil {
+			return "", nil, errgo.Notef(err, "can not read packed object "+id)
+		}
+
+		return kind, content, nil
+	}
+	if err != nil {
+		return "", nil, errgo.Notef(err, "can not open object "+id)
+	}
+	defer file.Close()
+
+	reader, err := zlib.NewReader(file)
+	if err != nil {
+		return "", nil, errgo.Notef(err, "can not decompress object "+id)
+	}
+
+	raw, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", nil, errgo.Notef(err, "can not read object "+id)
+	}
+
+	index := bytes.IndexByte(raw, 0)
+	if index == -1 {
+		return "", nil, errgo.New("object " + id + " has no header")
+	}
+
+	header := strings.SplitN(string(raw[:index]), " ", 2)
+
+	return header[0], raw[index+1:], nil
+}
+
+func (store objectStore) readKind(id, kind string) ([]byte, error) {
+	got, content, err := store.read(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if got != kind {
+		return nil, errgo.New("object " + id + " is a " + got + " not a " + kind)
+	}
+
+	return content, nil
+}
+
+func (store objectStore) commit(id string) (commitObject, error) {
+	var commit commitObject
+
+	content, err := store.readKind(id, "commit")
+	if err != nil {
+		return commit, err
+	}
+
+	parts := strings.SplitN(string(content), "\n\n", 2)
+	if len(parts) == 2 {
+		commit.Message = parts[1]
+	}
+
+	for _, line := range strings.Split(parts[0], "\n") {
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "tree":
+			commit.Tree = fields[1]
+		case "parent":
+			commit.Parents = append(commit.Parents, fields[1])
+		case "committer":
+			commit.TimeStamp = parseGitTime(fields[1])
+		}
+	}
+
+	return commit, nil
+}
+
+func (store objectStore) tree(id string) ([]treeEntry, error) {
+	content, err := store.readKind(id, "tree")
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []treeEntry
+	for len(content) != 0 {
+		space := bytes.IndexByte(content, ' ')
+		null := bytes.IndexByte(content, 0)
+		if space == -1 || null < space || len(content) < null+21 {
+			return nil, errgo.New("tree " + id + " is invalid")
+		}
+
+		entries = append(entries, treeEntry{
+			Mode: string(content[:space]),
+			Name: string(content[space+1 : null]),
+			ID:   hex.EncodeToString(content[null+1 : null+21]),
+		})
+
+		content = content[null+21:]
+	}
+
+	return entries, nil
+}
+
+// flatten adds all files of the tree to the files by their slash separated
+// path.
+func (store objectStore) flatten(id, prefix string, files map[string]treeFile) error {
+	entries, err := store.tree(id)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.Mode == modeTree {
+			err := store.flatten(entry.ID, prefix+entry.Name+"/", files)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		files[prefix+entry.Name] = treeFile{ID: entry.ID, Mode: entry.Mode}
+	}
+
+	return nil
+}
+
+// snapshot returns the id of the tree of all files in the datadir and the
+// files by their slash separated path. The objects are only written to the
+// store if write is true.
+func snapshot(store objectStore, datadir string, write bool) (string, map[string]treeFile, error) {
+	files := make(map[string]treeFile)
+
+	id, err := snapshotTree(store, datadir, "", nil, write, files)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if id == "" {
+		id, err = storeObject(store, "tree", nil, write)
+	}
+
+	return id, files, err
+}
+
+// snapshotTree returns the id of the tree of the folder or an empty id if the
+// folder contains no files as git does not record empty folders.
+func snapshotTree(store objectStore, datadir, prefix string, ignores []ignorePattern,
+	write bool, files map[string]treeFile) (string, error) {
+	folder := filepath.Join(datadir, filepath.FromSlash(prefix))
+
+	infos, err := ioutil.ReadDir(folder)
+	if err != nil {
+		return "", errgo.Notef(err, "can not read folder "+folder)
+	}
+
+	ignores = append(inherited(ignores), readIgnore(filepath.Join(folder, ".gitignore"))...)
+
+	var entries []treeEntry
+	for _, info := range infos {
+		name := info.Name()
+		path := prefix + name
+
+		if prefix == "" && name == ".git" {
+			continue
+		}
+
+		if ignored(ignores, name, info.IsDir()) {
+			continue
+		}
+
+		var entry treeEntry
+		var content []byte
+
+		switch {
+		case info.IsDir():
+			id, err := snapshotTree(store, datadir, path+"/", ignores, write, files)
+			if err != nil {
+				return "", err
+			}
+			if id == "" {
+				continue
+			}
+
+			entries = append(entries, treeEntry{Mode: modeTree, Name: name, ID: id})
+			continue
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(filepath.Join(folder, name))
+			if err != nil {
+				return "", errgo.Notef(err, "can not read link "+path)
+			}
+
+			entry.Mode, content = modeSymlink, []byte(target)
+		case info.Mode().IsRegular():
+			content, err = ioutil.ReadFile(filepath.Join(folder, name))
+			if err != nil {
+				return "", errgo.Notef(err, "can not read file "+path)
+			}
+
+			entry.Mode = modeFile
+			if info.Mode()&0111 != 0 {
+				entry.Mode = modeExecutable
+			}
+		default:
+			continue
+		}
+
+		entry.Name = name
+		entry.ID, err = storeObject(store, "blob", content, write)
+		if err != nil {
+			return "", err
+		}
+
+		entries = append(entries, entry)
+		files[path] = treeFile{ID: entry.ID, Mode: entry.Mode, Info: info}
+	}
+
+	if len(entries) == 0 {
+		return "", nil
+	}
+
+	sort.Sort(treeEntriesByName(entries))
+
+	tree := new(bytes.Buffer)
+	for _, entry := range entries {
+		id, _ := hex.DecodeString(entry.ID)
+
+		tree.WriteString(entry.Mode + " " + entry.Name + "\x00")
+		tree.Write(id)
+	}
+
+	return storeObject(store, "tree", tree.Bytes(), write)
+}
+
+func storeObject(store objectStore, kind string, content []byte, write bool) (string, error) {
+	if !write {
+		id, _ := hashObject(kind, content)
+		return id, nil
+	}
+
+	return store.write(kind, content)
+}
+
+type treeEntriesByName []treeEntry
+
+func (entries treeEntriesByName) Len() int {
+	return len(entries)
+}
+
+func (entries treeEntriesByName) Swap(i, j int) {
+	entries[i], entries[j] = entries[j], entries[i]
+}
+
+// Less sorts folders as if their name ended with a slash like git does.
+func (entries treeEntriesByName) Less(i, j int) bool {
+	return entries[i].sortName() < entries[j].sortName()
+}
+
+func (entry treeEntry) sortName() string {
+	if entry.Mode == modeTree {
+		return entry.Name + "/"
+	}
+
+	return entry.Name
+}
+
+// ignorePattern is a pattern of a .gitignore file. Negated patterns and
+// patterns that contain a slash in the middle are not supported.
+type ignorePattern struct {
+	Pattern  string
+	Folder   bool
+	Anchored bool
+}
+
+func readIgnore(path string) []ignorePattern {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var patterns []ignorePattern
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		pattern := ignorePattern{Pattern: line}
+		if strings.HasSuffix(pattern.Pattern, "/") {
+			pattern.Folder = true
+			pattern.Pattern = strings.TrimSuffix(pattern.Pattern, "/")
+		}
+		if strings.HasPrefix(pattern.Pattern, "/") {
+			pattern.Anchored = true
+			pattern.Pattern = strings.TrimPrefix(pattern.Pattern, "/")
+		}
+
+		if strings.Contains(pattern.Pattern, "/") {
+			continue
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns
+}
+
+// inherited returns the patterns that also apply to subfolders.
+func inherited(patterns []ignorePattern) []ignorePattern {
+	var result []ignorePattern
+	for _, pattern := range patterns {
+		if !pattern.Anchored {
+			result = append(result, pattern)
+		}
+	}
+
+	return result
+}
+
+func ignored(patterns []ignorePattern, name string, folder bool) bool {
+	for _, pattern := range patterns {
+		if pattern.Folder && !folder {
+			continue
+		}
+
+		if ok, _ := filepath.Match(pattern.Pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseGitTime parses the time at the end of the author or committer line of
+// a commit.
+func parseGitTime(line string) time.Time {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return time.Time{}
+	}
+
+	seconds, err := strconv.ParseInt(fields[len(fields)-2], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(seconds, 0)
+}
@@ -0,0 +1,349 @@
+package vcs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/juju/errgo"
+)
+
+// Object types in pack files. The deltas contain the changes to a base object
+// which is either referenced by its offset in the same pack or by its id.
+const (
+	packCommit      = 1
+	packTree        = 2
+	packBlob        = 3
+	packTag         = 4
+	packOffsetDelta = 6
+	packRefDelta    = 7
+)
+
+var packKinds = map[byte]string{
+	packCommit: "commit",
+	packTree:   "tree",
+	packBlob:   "blob",
+	packTag:    "tag",
+}
+
+// packIndex is a version 2 index of a pack file. It contains the sorted ids
+// of all objects in the pack and their offsets in the pack file.
+type packIndex struct {
+	pack    string
+	ids     []byte
+	offsets []byte
+	large   []byte
+}
+
+// packIndexes loads the indexes of the packs of a repository once.
+type packIndexes struct {
+	once    sync.Once
+	indexes []packIndex
+	err     error
+}
+
+func (packs *packIndexes) load(gitdir string) ([]packIndex, error) {
+	packs.once.Do(func() {
+		packs.indexes, packs.err = readPackIndexes(gitdir)
+	})
+
+	return packs.indexes, packs.err
+}
+
+func readPackIndexes(gitdir string) ([]packIndex, error) {
+	paths, err := filepath.Glob(filepath.Join(gitdir, "objects", "pack", "pack-*.idx"))
+	if err != nil {
+		return nil, errgo.Notef(err, "can not list pack indexes")
+	}
+
+	var indexes []packIndex
+	for _, path := range paths {
+		index, err := readPackIndex(path)
+		if err != nil {
+			return nil, errgo.Notef(err, "can not read pack index "+path)
+		}
+
+		indexes = append(indexes, index)
+	}
+
+	return indexes, nil
+}
+
+func readPackIndex(path string) (packIndex, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return packIndex{}, err
+	}
+
+	const header = 8 + 256*4
+	if len(raw) < header || !bytes.Equal(raw[:8], []byte("\xfftOc\x00\x00\x00\x02")) {
+		return packIndex{}, errgo.New("only version 2 pack indexes are supported")
+	}
+
+	count := int(binary.BigEndian.Uint32(raw[header-4:]))
+	ids := header
+	crcs := ids + count*20
+	offsets := crcs + count*4
+	large := offsets + count*4
+
+	// The index ends with the checksums of the pack and of the index.
+	if len(raw) < large+40 {
+		return packIndex{}, errgo.New("pack index is truncated")
+	}
+
+	return packIndex{
+		pack:    path[:len(path)-len(".idx")] + ".pack",
+		ids:     raw[ids:crcs],
+		offsets: raw[offsets:large],
+		large:   raw[large : len(raw)-40],
+	}, nil
+}
+
+// offset returns the offset of the object in the pack file.
+func (index packIndex) offset(id []byte) (int64, bool) {
+	count := len(index.ids) / 20
+	i := sort.Search(count, func(i int) bool {
+		return bytes.Compare(index.ids[i*20:i*20+20], id) >= 0
+	})
+	if i == count || !bytes.Equal(index.ids[i*20:i*20+20], id) {
+		return 0, false
+	}
+
+	offset := binary.BigEndian.Uint32(index.offsets[i*4:])
+	if offset&0x80000000 == 0 {
+		return int64(offset), true
+	}
+
+	// Offsets of packs larger than 2GiB are stored in the table of large
+	// offsets.
+	position := int(offset&0x7fffffff) * 8
+	if position+8 > len(index.large) {
+		return 0, false
+	}
+
+	return int64(binary.BigEndian.Uint64(index.large[position:])), true
+}
+
+// packed returns the pack file and offset of the object. The pack file is
+// empty if the object is in no pack.
+func (store objectStore) packed(id string) (string, int64, error) {
+	raw, err := hex.DecodeString(id)
+	if err != nil {
+		return "", 0, errgo.Notef(err, "invalid object id "+id)
+	}
+
+	indexes, err := store.packs.load(store.gitdir)
+	if err != nil {
+		return "", 0, err
+	}
+
+	for _, index := range indexes {
+		if offset, ok := index.offset(raw); ok {
+			return index.pack, offset, nil
+		}
+	}
+
+	return "", 0, nil
+}
+
+// readPacked returns the kind and content of the object at the offset in the
+// pack file.
+func (store objectStore) readPacked(pack string, offset int64) (string, []byte, error) {
+	file, err := os.Open(pack)
+	if err != nil {
+		return "", nil, errgo.Notef(err, "can not open pack")
+	}
+	defer file.Close()
+
+	return store.readPackObject(file, offset)
+}
+
+func (store objectStore) readPackObject(file *os.File, offset int64) (string, []byte, error) {
+	reader := bufio.NewReader(io.NewSectionReader(file, offset, math.MaxInt64-offset))
+	position := strconv.FormatInt(offset, 10)
+
+	// The header contains the type and the size of the object as a variable
+	// length integer.
+	b, err := reader.ReadByte()
+	if err != nil {
+		return "", nil, errgo.Notef(err, "can not read object header at "+position)
+	}
+
+	kind := (b >> 4) & 7
+	size := uint64(b & 15)
+	for shift := uint(4); b&0x80 != 0; shift += 7 {
+		b, err = reader.ReadByte()
+		if err != nil {
+			return "", nil, errgo.Notef(err, "can not read object header at "+position)
+		}
+
+		size |= uint64(b&0x7f) << shift
+	}
+
+	var base []byte
+	var name string
+	switch kind {
+	case packOffsetDelta:
+		b, err = reader.ReadByte()
+		relative := int64(b & 0x7f)
+		for err == nil && b&0x80 != 0 {
+			b, err = reader.ReadByte()
+			relative = (relative+1)<<7 | int64(b&0x7f)
+		}
+		if err != nil {
+			return "", nil, errgo.Notef(err, "can not read base offset at "+position)
+		}
+		if relative <= 0 || relative > offset {
+			return "", nil, errgo.New("invalid base offset at " + position)
+		}
+
+		name, base, err = store.readPackObject(file, offset-relative)
+		if err != nil {
+			return "", nil, errgo.Notef(err, "can not read base object of "+position)
+		}
+	case packRefDelta:
+		id := make([]byte, 20)
+		_, err = io.ReadFull(reader, id)
+		if err != nil {
+			return "", nil, errgo.Notef(err, "can not read base id at "+position)
+		}
+
+		name, base, err = store.read(hex.EncodeToString(id))
+		if err != nil {
+			return "", nil, errgo.Notef(err, "can not read base object of "+position)
+		}
+	default:
+		var ok bool
+		name, ok = packKinds[kind]
+		if !ok {
+			return "", nil, errgo.New("unknown object type " + strconv.Itoa(int(kind)) + " at " + position)
+		}
+	}
+
+	inflated, err := zlib.NewReader(reader)
+	if err != nil {
+		return "", nil, errgo.Notef(err, "can not decompress object at "+position)
+	}
+
+	content, err := ioutil.ReadAll(inflated)
+	if err != nil {
+		return "", nil, errgo.Notef(err, "can not decompress object at "+position)
+	}
+
+	if uint64(len(content)) != size {
+		return "", nil, errgo.New("object at " + position + " has the wrong size")
+	}
+
+	if base != nil {
+		content, err = applyDelta(base, content)
+		if err != nil {
+			return "", nil, errgo.Notef(err, "can not apply delta at "+position)
+		}
+	}
+
+	return name, content, nil
+}
+
+// applyDelta builds the object from the base object and the delta. The delta
+// starts with the sizes of the base and the result followed by instructions
+// that either copy a part of the base or insert new data.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	next := func() (byte, error) {
+		if len(delta) == 0 {
+			return 0, errgo.New("delta is truncated")
+		}
+
+		b := delta[0]
+		delta = delta[1:]
+
+		return b, nil
+	}
+
+	size := func() (uint64, error) {
+		var value uint64
+		for shift := uint(0); ; shift += 7 {
+			b, err := next()
+			if err != nil {
+				return 0, err
+			}
+
+			value |= uint64(b&0x7f) << shift
+			if b&0x80 == 0 {
+				return value, nil
+			}
+		}
+	}
+
+	baseSize, err := size()
+	if err != nil {
+		return nil, err
+	}
+	if baseSize != uint64(len(base)) {
+		return nil, errgo.New("delta does not match the size of the base object")
+	}
+
+	resultSize, err := size()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, resultSize)
+	for len(delta) != 0 {
+		op, _ := next()
+
+		switch {
+		case op&0x80 != 0:
+			var offset, length uint64
+			for i := uint(0); i < 4; i++ {
+				if op&(1<<i) != 0 {
+					b, err := next()
+					if err != nil {
+						return nil, err
+					}
+					offset |= uint64(b) << (8 * i)
+				}
+			}
+			for i := uint(0); i < 3; i++ {
+				if op&(0x10<<i) != 0 {
+					b, err := next()
+					if err != nil {
+						return nil, err
+					}
+					length |= uint64(b) << (8 * i)
+				}
+			}
+			if length == 0 {
+				length = 0x10000
+			}
+
+			if offset+length > uint64(len(base)) {
+				return nil, errgo.New("delta copies outside of the base object")
+			}
+			out = append(out, base[offset:offset+length]...)
+		case op != 0:
+			if int(op) > len(delta) {
+				return nil, errgo.New("delta is truncated")
+			}
+			out = append(out, delta[:op]...)
+			delta = delta[op:]
+		default:
+			return nil, errgo.New("delta contains the reserved instruction 0")
+		}
+	}
+
+	if uint64(len(out)) != resultSize {
+		return nil, errgo.New("delta result has the wrong size")
+	}
+
+	return out, nil
+}
@@ -0,0 +1,197 @@
+package vcs
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func tmpGoGit(t *testing.T) (string, Backend) {
+	datadir, err := ioutil.TempDir("", "vcs_test_gogit")
+	if err != nil {
+		t.Fatal("can not open tmpdir: ", err)
+	}
+
+	backend := NewGoGit(datadir)
+	err = backend.Init()
+	if err != nil {
+		t.Fatal("can not init repository: ", err)
+	}
+
+	return datadir, backend
+}
+
+func Test_GoGitCommit(t *testing.T) {
+	datadir, backend := tmpGoGit(t)
+
+	err := os.MkdirAll(filepath.Join(datadir, "Test", "Sub"), 0755)
+	if err != nil {
+		t.Fatal("can not create folder: ", err)
+	}
+	writeFile(t, datadir, "Test.csv")
+	writeFile(t, datadir, "Test/Sub.csv")
+	writeFile(t, datadir, "Test/Sub/Project.csv")
+	writeFile(t, datadir, "ignored.tmp")
+	writeFile(t, datadir, ".gitignore")
+	err = ioutil.WriteFile(filepath.Join(datadir, ".gitignore"), []byte("*.tmp\n"), 0644)
+	if err != nil {
+		t.Fatal("can not write .gitignore: ", err)
+	}
+
+	status, err := backend.Status()
+	testhelper.CompareGotExpected(t, err, status, []string{
+		".gitignore", "Test.csv", "Test/Sub.csv", "Test/Sub/Project.csv",
+	})
+
+	for _, message := range []string{"first", "nothing changed"} {
+		err = backend.Commit(message)
+		if err != nil {
+			t.Fatal("can not commit: ", err)
+		}
+	}
+
+	status, err = backend.Status()
+	testhelper.CompareGotExpected(t, err, status, []string(nil))
+
+	writeFile(t, datadir, "Other.csv")
+	err = os.Remove(filepath.Join(datadir, "Test.csv"))
+	if err != nil {
+		t.Fatal("can not remove file: ", err)
+	}
+
+	status, err = backend.Status()
+	testhelper.CompareGotExpected(t, err, status, []string{"Other.csv", "Test.csv"})
+
+	err = backend.Commit("second")
+	if err != nil {
+		t.Fatal("can not commit: ", err)
+	}
+
+	log, err := backend.Log(0)
+	if err != nil {
+		t.Fatal("can not get log: ", err)
+	}
+
+	var messages []string
+	for _, entry := range log {
+		messages = append(messages, entry.Message)
+	}
+	testhelper.CompareGotExpected(t, nil, messages, []string{"second", "first"})
+
+	last, err := backend.Log(1)
+	testhelper.CompareGotExpected(t, err, last, log[:1])
+
+	if _, err := exec.LookPath("git"); err != nil {
+		return
+	}
+
+	// The repository has to be readable by git.
+	_, err = git(datadir, "fsck", "--strict")
+	if err != nil {
+		t.Fatal("git can not check the repository: ", err)
+	}
+
+	gitStatus, err := NewGit(datadir).Status()
+	testhelper.CompareGotExpected(t, err, gitStatus, []string(nil))
+
+	gitLog, err := NewGit(datadir).Log(0)
+	testhelper.CompareGotExpected(t, err, gitLog, log)
+}
+
+func Test_GoGitNotInitialized(t *testing.T) {
+	datadir, err := ioutil.TempDir("", "vcs_test_gogit")
+	if err != nil {
+		t.Fatal("can not open tmpdir: ", err)
+	}
+
+	_, err = NewGoGit(datadir).Log(1)
+	if err == nil {
+		t.Fatal("expected an error for a datadir without repository")
+	}
+}
+
+func Test_GoGitAfterGit(t *testing.T) {
+	datadir := tmpRepo(t)
+
+	writeFile(t, datadir, "Test.csv")
+	err := NewGit(datadir).Commit("git")
+	if err != nil {
+		t.Fatal("can not commit with git: ", err)
+	}
+
+	backend := NewGoGit(datadir)
+	writeFile(t, datadir, "Other.csv")
+	err = backend.Commit("gogit")
+	if err != nil {
+		t.Fatal("can not commit with gogit: ", err)
+	}
+
+	status, err := NewGit(datadir).Status()
+	testhelper.CompareGotExpected(t, err, status, []string(nil))
+
+	output, err := git(datadir, "log", "--format=%s")
+	testhelper.CompareGotExpected(t, err, output, "gogit\ngit\n")
+
+	// Both commits use the identity from the config of the repository.
+	output, err = git(datadir, "log", "--format=%an <%ae>")
+	testhelper.CompareGotExpected(t, err, output, "lablog <lablog@example.com>\nlablog <lablog@example.com>\n")
+}
+
+func Test_GoGitPacked(t *testing.T) {
+	datadir := tmpRepo(t)
+
+	// Several versions of a growing file so git stores them as deltas.
+	var content string
+	for i := 0; i != 20; i++ {
+		content += strings.Repeat("entry "+strconv.Itoa(i)+" ", 20) + "\n"
+		err := ioutil.WriteFile(filepath.Join(datadir, "Test.csv"), []byte(content), 0644)
+		if err != nil {
+			t.Fatal("can not write file: ", err)
+		}
+
+		err = NewGit(datadir).Commit("commit " + strconv.Itoa(i))
+		if err != nil {
+			t.Fatal("can not commit with git: ", err)
+		}
+	}
+
+	_, err := git(datadir, "gc", "-q", "--aggressive", "--prune=now")
+	if err != nil {
+		t.Fatal("can not pack repository: ", err)
+	}
+
+	loose, err := filepath.Glob(filepath.Join(datadir, ".git", "objects", "??", "*"))
+	testhelper.CompareGotExpected(t, err, len(loose), 0)
+
+	gitLog, err := NewGit(datadir).Log(0)
+	if err != nil {
+		t.Fatal("can not get log with git: ", err)
+	}
+
+	backend := NewGoGit(datadir)
+	log, err := backend.Log(0)
+	testhelper.CompareGotExpected(t, err, log, gitLog)
+
+	status, err := backend.Status()
+	testhelper.CompareGotExpected(t, err, status, []string(nil))
+
+	writeFile(t, datadir, "Other.csv")
+	err = backend.Commit("gogit")
+	if err != nil {
+		t.Fatal("can not commit with gogit: ", err)
+	}
+
+	_, err = git(datadir, "fsck", "--strict")
+	if err != nil {
+		t.Fatal("git can not check the repository: ", err)
+	}
+
+	gitStatus, err := NewGit(datadir).Status()
+	testhelper.CompareGotExpected(t, err, gitStatus, []string(nil))
+}
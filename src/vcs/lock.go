@@ -0,0 +1,86 @@
+package vcs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+// LockFileName is the name of the file in the git directory of the datadir
+// that is locked while lablog runs git commands.
+const LockFileName = "lablog.lock"
+
+// LockTimeout is the time to wait for another process to release the lock
+// file.
+var LockTimeout = 10 * time.Second
+
+// mutex serializes the git commands of the process. The lock file only
+// serializes the commands between processes.
+var mutex sync.Mutex
+
+// lock acquires the lock for the repository in the datadir and returns the
+// function that releases it again.
+func lock(datadir string) (func(), error) {
+	mutex.Lock()
+
+	gitdir, err := git(datadir, "rev-parse", "--git-dir")
+	if err != nil {
+		mutex.Unlock()
+		return nil, errgo.Notef(err, "can not get git directory")
+	}
+
+	gitdir = strings.TrimSpace(gitdir)
+	if !filepath.IsAbs(gitdir) {
+		gitdir = filepath.Join(datadir, gitdir)
+	}
+
+	return lockGitDir(gitdir)
+}
+
+// lockGitDir locks the lock file in the git directory while the mutex is
+// already held. The mutex is released if the lock file can not be locked.
+func lockGitDir(gitdir string) (func(), error) {
+	file, err := lockFile(filepath.Join(gitdir, LockFileName), LockTimeout)
+	if err != nil {
+		mutex.Unlock()
+		return nil, err
+	}
+
+	return func() {
+		unlockFile(file)
+		file.Close()
+		mutex.Unlock()
+	}, nil
+}
+
+// lockFile opens and locks the file at the path. It retries with an
+// increasing delay until the timeout is reached.
+func lockFile(path string, timeout time.Duration) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, LockError{Path: path, Err: err}
+	}
+
+	deadline := time.Now().Add(timeout)
+	delay := time.Millisecond
+	for {
+		err = tryLockFile(file)
+		if err == nil {
+			return file, nil
+		}
+
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, LockError{Path: path, Err: err}
+		}
+
+		time.Sleep(delay)
+		if delay < 100*time.Millisecond {
+			delay *= 2
+		}
+	}
+}
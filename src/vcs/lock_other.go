@@ -0,0 +1,18 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package vcs
+
+import "os"
+
+// There are no advisory file locks on these systems so git commands are only
+// serialized inside of the process. Concurrent processes still retry if git
+// reports a locked repository.
+
+func tryLockFile(file *os.File) error {
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,34 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package vcs
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_LockFile(t *testing.T) {
+	datadir := tmpRepo(t)
+	path := filepath.Join(datadir, ".git", LockFileName)
+
+	file, err := lockFile(path, time.Second)
+	if err != nil {
+		t.Fatal("can not lock file: ", err)
+	}
+	defer file.Close()
+
+	_, err = lockFile(path, 10*time.Millisecond)
+	if _, ok := err.(LockError); !ok {
+		t.Fatal("expected a lock error for the locked file but got: ", err)
+	}
+
+	unlockFile(file)
+
+	second, err := lockFile(path, time.Second)
+	if err != nil {
+		t.Fatal("can not lock file after it was unlocked: ", err)
+	}
+	second.Close()
+}
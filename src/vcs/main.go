@@ -2,64 +2,165 @@ package vcs
 
 import (
 	"bytes"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/AlexanderThaller/lablog/src/data"
 	"github.com/juju/errgo"
 )
 
+var (
+	// Retries is the number of times a git command is retried if git reports
+	// that the repository is locked by another git process.
+	Retries = 8
+	// RetryDelay is the delay before the first retry. It is doubled after
+	// every retry.
+	RetryDelay = 10 * time.Millisecond
+)
+
 //Commit will add and commit the given entry into the repository that lays unter
 //the given datadir.
 func Commit(datadir string, project data.ProjectName, entry data.Entry) error {
-	err := gitAdd(datadir, ".")
-	if err != nil {
-		return errgo.Notef(err, "can not add file to repository")
-	}
-
 	message := project.String() + " - " + entry.Type().String() + " - " +
 		entry.GetTimeStamp().Format(data.TimeStampFormat)
 
-	err = gitCommit(datadir, message)
+	return CommitMessage(datadir, message)
+}
+
+//CommitMessage will record all changes in the repository that lays under the
+//given datadir with the given message using the backend selected with
+//BackendName.
+func CommitMessage(datadir, message string) error {
+	backend, err := Open(datadir)
+	if err != nil {
+		return errgo.Notef(err, "can not open vcs backend")
+	}
+
+	err = backend.Commit(message)
 	if err != nil {
-		return errgo.Notef(err, "can not commit file to repository")
+		return errgo.Notef(err, "can not commit changes")
 	}
 
 	return nil
 }
 
-func gitAdd(datadir, filename string) error {
-	command := exec.Command("git", "add", filename)
-	command.Dir = datadir
+// appendLine appends the line to the file if the file does not contain it
+// yet. The file is created if it does not exist.
+func appendLine(path, line string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return errgo.Notef(err, "can not read file")
+	}
 
-	stderr := new(bytes.Buffer)
-	command.Stderr = stderr
+	for _, existing := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(existing) == line {
+			return nil
+		}
+	}
+
+	if len(content) != 0 && !strings.HasSuffix(string(content), "\n") {
+		content = append(content, '\n')
+	}
+	content = append(content, line+"\n"...)
 
-	err := command.Run()
+	err = ioutil.WriteFile(path, content, 0644)
 	if err != nil {
-		return errgo.Notef(errgo.Notef(err, "can not add file with git"),
-			stderr.String())
+		return errgo.Notef(err, "can not write file")
 	}
 
-	// Give git time to add everything and remove the lockfile.
-	time.Sleep(5 * time.Millisecond)
 	return nil
 }
 
-func gitCommit(datadir, message string) error {
-	command := exec.Command("git", "commit", "-m", message)
-	command.Dir = datadir
+func gitAdd(datadir, filename string) error {
+	_, err := git(datadir, "add", filename)
+	if err != nil {
+		return errgo.Notef(err, "can not add file with git")
+	}
 
-	stderr := new(bytes.Buffer)
-	command.Stderr = stderr
+	return nil
+}
+
+// gitStaged returns true if the index of the repository contains changes that
+// are not committed yet.
+func gitStaged(datadir string) (bool, error) {
+	_, err := git(datadir, "diff", "--cached", "--quiet")
+	if err == nil {
+		return false, nil
+	}
 
-	err := command.Run()
+	if gerr, ok := err.(GitError); ok && exitCode(gerr.Err) == 1 {
+		return true, nil
+	}
+
+	return false, errgo.Notef(err, "can not diff index with git")
+}
+
+func gitCommit(datadir, message string) error {
+	_, err := git(datadir, "commit", "-m", message)
 	if err != nil {
-		return errgo.Notef(errgo.Notef(err, "can not add file with git"),
-			stderr.String())
+		return errgo.Notef(err, "can not commit with git")
 	}
 
-	// Give git time to commit everything and remove the lockfile.
-	time.Sleep(5 * time.Millisecond)
 	return nil
 }
+
+// git runs git with the given arguments in the datadir and returns the
+// output. If git can not lock the repository because another git process is
+// running the command is retried with an increasing delay. The returned error
+// is a GitError or a LockedError if the repository stayed locked.
+func git(datadir string, args ...string) (string, error) {
+	delay := RetryDelay
+
+	for attempt := 0; ; attempt++ {
+		command := exec.Command("git", args...)
+		command.Dir = datadir
+
+		stdout := new(bytes.Buffer)
+		stderr := new(bytes.Buffer)
+		command.Stdout = stdout
+		command.Stderr = stderr
+
+		err := command.Run()
+		if err == nil {
+			return stdout.String(), nil
+		}
+
+		gerr := GitError{Args: args, Stderr: stderr.String(), Err: err}
+		if !isLockMessage(gerr.Stderr) {
+			return "", gerr
+		}
+
+		if attempt == Retries {
+			return "", LockedError{Attempts: attempt + 1, Err: gerr}
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// isLockMessage returns true if git failed because a lock file of the
+// repository like index.lock already exists.
+func isLockMessage(stderr string) bool {
+	return strings.Contains(stderr, ".lock") &&
+		(strings.Contains(stderr, "File exists") || strings.Contains(stderr, "cannot lock ref"))
+}
+
+func exitCode(err error) int {
+	exiterr, ok := err.(*exec.ExitError)
+	if !ok {
+		return -1
+	}
+
+	status, ok := exiterr.Sys().(interface {
+		ExitStatus() int
+	})
+	if !ok {
+		return -1
+	}
+
+	return status.ExitStatus()
+}
@@ -0,0 +1,149 @@
+package vcs
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func requireGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+}
+
+func tmpRepo(t *testing.T) string {
+	requireGit(t)
+
+	datadir, err := ioutil.TempDir("", "vcs_test")
+	if err != nil {
+		t.Fatal("can not open tmpdir: ", err)
+	}
+
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "lablog"},
+		{"config", "user.email", "lablog@example.com"},
+	} {
+		_, err := git(datadir, args...)
+		if err != nil {
+			t.Fatal("can not setup repository: ", err)
+		}
+	}
+
+	return datadir
+}
+
+func writeFile(t *testing.T, datadir, name string) {
+	err := ioutil.WriteFile(filepath.Join(datadir, name), []byte(name+"\n"), 0644)
+	if err != nil {
+		t.Fatal("can not write file: ", err)
+	}
+}
+
+func Test_CommitMessageConcurrent(t *testing.T) {
+	datadir := tmpRepo(t)
+
+	const count = 20
+
+	var wait sync.WaitGroup
+	errs := make(chan error, count)
+	for i := 0; i != count; i++ {
+		wait.Add(1)
+		go func(i int) {
+			defer wait.Done()
+
+			name := "file" + strconv.Itoa(i)
+			writeFile(t, datadir, name)
+			errs <- CommitMessage(datadir, name)
+		}(i)
+	}
+	wait.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal("can not commit: ", err)
+		}
+	}
+
+	files, err := git(datadir, "ls-tree", "--name-only", "HEAD")
+	if err != nil {
+		t.Fatal("can not list files of head: ", err)
+	}
+
+	got := len(strings.Fields(files))
+	if got != count {
+		t.Fatalf("expected %d committed files but got %d", count, got)
+	}
+
+	status, err := git(datadir, "status", "--porcelain")
+	if err != nil {
+		t.Fatal("can not get status: ", err)
+	}
+
+	if status != "" {
+		t.Fatal("expected a clean repository but got: ", status)
+	}
+}
+
+func Test_CommitMessageNothingToCommit(t *testing.T) {
+	datadir := tmpRepo(t)
+
+	writeFile(t, datadir, "file")
+	err := CommitMessage(datadir, "first")
+	if err != nil {
+		t.Fatal("can not commit: ", err)
+	}
+
+	err = CommitMessage(datadir, "second")
+	if err != nil {
+		t.Fatal("expected no error without changes but got: ", err)
+	}
+}
+
+func Test_CommitMessageIndexLock(t *testing.T) {
+	datadir := tmpRepo(t)
+	writeFile(t, datadir, "file")
+
+	indexlock := filepath.Join(datadir, ".git", "index.lock")
+	err := ioutil.WriteFile(indexlock, nil, 0644)
+	if err != nil {
+		t.Fatal("can not create index lock: ", err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		os.Remove(indexlock)
+	}()
+
+	err = CommitMessage(datadir, "retried")
+	if err != nil {
+		t.Fatal("expected the commit to succeed after the lock was removed: ", err)
+	}
+}
+
+func Test_CommitMessageLocked(t *testing.T) {
+	datadir := tmpRepo(t)
+	writeFile(t, datadir, "file")
+
+	err := ioutil.WriteFile(filepath.Join(datadir, ".git", "index.lock"), nil, 0644)
+	if err != nil {
+		t.Fatal("can not create index lock: ", err)
+	}
+
+	retries := Retries
+	Retries = 2
+	defer func() { Retries = retries }()
+
+	err = CommitMessage(datadir, "locked")
+	if !IsLocked(err) {
+		t.Fatal("expected a locked error but got: ", err)
+	}
+}
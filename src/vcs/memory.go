@@ -0,0 +1,81 @@
+package vcs
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Memory is a backend that keeps the commits in memory. It does not look at
+// the files in the datadir and is meant to be used in tests instead of
+// running git.
+type Memory struct {
+	mutex   sync.Mutex
+	commits []LogEntry
+	moves   [][2]string
+}
+
+// NewMemory returns an empty memory backend. Register it in Backends to use
+// it for all datadirs:
+//
+//	memory := vcs.NewMemory()
+//	vcs.Backends["memory"] = func(string) vcs.Backend { return memory }
+//	vcs.BackendName = "memory"
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (memory *Memory) Init() error {
+	return nil
+}
+
+func (memory *Memory) Commit(message string) error {
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+
+	memory.commits = append(memory.commits, LogEntry{
+		ID:        strconv.Itoa(len(memory.commits) + 1),
+		TimeStamp: time.Now(),
+		Message:   message,
+	})
+
+	return nil
+}
+
+// Status always returns no changes as the memory backend does not track
+// files.
+func (memory *Memory) Status() ([]string, error) {
+	return nil, nil
+}
+
+func (memory *Memory) Log(limit int) ([]LogEntry, error) {
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+
+	var out []LogEntry
+	for i := len(memory.commits) - 1; i >= 0; i-- {
+		if limit != 0 && len(out) == limit {
+			break
+		}
+
+		out = append(out, memory.commits[i])
+	}
+
+	return out, nil
+}
+
+func (memory *Memory) Move(from, to string) error {
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+
+	memory.moves = append(memory.moves, [2]string{from, to})
+	return nil
+}
+
+// Moves returns all moves recorded with Move.
+func (memory *Memory) Moves() [][2]string {
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+
+	return append([][2]string{}, memory.moves...)
+}
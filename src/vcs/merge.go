@@ -0,0 +1,159 @@
+package vcs
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AlexanderThaller/dbfiles"
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/juju/errgo"
+)
+
+const (
+	// MergeDriverName is the name of the merge driver in the git config.
+	MergeDriverName = "lablog"
+	// MergeDriverAttribute is the line in .gitattributes that uses the merge
+	// driver for project files.
+	MergeDriverAttribute = "*.csv merge=" + MergeDriverName
+)
+
+// Merge writes the union of the entries of both changed versions of a project
+// file sorted by timestamp to the writer. Entries of the base version that
+// were removed or edited on one side are left out.
+func Merge(writer io.Writer, base, ours, theirs io.Reader) error {
+	var versions [3]data.Entries
+	for i, reader := range []io.Reader{base, ours, theirs} {
+		entries, err := readEntries(reader)
+		if err != nil {
+			return err
+		}
+
+		versions[i] = entries
+	}
+
+	removed := make(map[string]struct{})
+	for _, side := range versions[1:] {
+		keys := entryKeys(side)
+
+		for key := range entryKeys(versions[0]) {
+			if _, ok := keys[key]; !ok {
+				removed[key] = struct{}{}
+			}
+		}
+	}
+
+	var out data.Entries
+	for _, entry := range data.MergeEntries(versions[1], versions[2]) {
+		if _, ok := removed[entryKey(entry)]; ok {
+			continue
+		}
+
+		out = append(out, entry)
+	}
+
+	return writeEntries(writer, out)
+}
+
+// MergeFiles merges the project files like git expects from a merge driver.
+// The result is written to the file of our version.
+func MergeFiles(base, ours, theirs string) error {
+	var readers []io.Reader
+	for _, path := range []string{base, ours, theirs} {
+		file, err := os.Open(path)
+		if err != nil {
+			return errgo.Notef(err, "can not open file")
+		}
+		defer file.Close()
+
+		readers = append(readers, file)
+	}
+
+	merged := new(bytes.Buffer)
+	err := Merge(merged, readers[0], readers[1], readers[2])
+	if err != nil {
+		return errgo.Notef(err, "can not merge project files")
+	}
+
+	err = ioutil.WriteFile(ours, merged.Bytes(), 0640)
+	if err != nil {
+		return errgo.Notef(err, "can not write merged project file")
+	}
+
+	return nil
+}
+
+// InstallMergeDriver configures the repository in the datadir to merge
+// project files with the given command. The attribute is added to the
+// .gitattributes file of the datadir which has to be committed afterwards.
+func InstallMergeDriver(datadir, command string) error {
+	err := appendLine(filepath.Join(datadir, ".gitattributes"), MergeDriverAttribute)
+	if err != nil {
+		return errgo.Notef(err, "can not add merge driver to .gitattributes")
+	}
+
+	unlock, err := lock(datadir)
+	if err != nil {
+		return errgo.Notef(err, "can not lock repository")
+	}
+	defer unlock()
+
+	prefix := "merge." + MergeDriverName + "."
+	for _, option := range [][2]string{
+		{prefix + "name", "lablog project files"},
+		{prefix + "driver", command + " git-merge-driver %O %A %B"},
+	} {
+		_, err = git(datadir, "config", option[0], option[1])
+		if err != nil {
+			return errgo.Notef(err, "can not set git config")
+		}
+	}
+
+	return nil
+}
+
+func readEntries(reader io.Reader) (data.Entries, error) {
+	records, err := dbfiles.CSV{}.Read(reader)
+	if err != nil {
+		return nil, errgo.Notef(err, "can not read project file")
+	}
+
+	var entries data.Entries
+	for _, record := range records {
+		entry, err := data.ParseEntry(record)
+		if err != nil {
+			return nil, errgo.Notef(err, "can not parse entry from value")
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func writeEntries(writer io.Writer, entries data.Entries) error {
+	for _, entry := range entries {
+		err := dbfiles.CSV{}.Write(writer, entry.Values())
+		if err != nil {
+			return errgo.Notef(err, "can not write entry")
+		}
+	}
+
+	return nil
+}
+
+func entryKeys(entries data.Entries) map[string]struct{} {
+	keys := make(map[string]struct{})
+	for _, entry := range entries {
+		keys[entryKey(entry)] = struct{}{}
+	}
+
+	return keys
+}
+
+func entryKey(entry data.Entry) string {
+	return strings.Join(entry.Values(), "\x00")
+}
@@ -0,0 +1,70 @@
+package vcs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func entriesFile(entries ...data.Entry) *bytes.Buffer {
+	buffer := new(bytes.Buffer)
+	writeEntries(buffer, entries)
+
+	return buffer
+}
+
+func Test_Merge(t *testing.T) {
+	kept := testhelper.GetTestNote(0, "kept")
+	removed := testhelper.GetTestNote(1, "removed")
+	edited := testhelper.GetTestNote(2, "before edit")
+	ours := testhelper.GetTestNote(3, "ours\nwith \"quotes\"")
+	theirs := testhelper.GetTestTodo(4, "theirs")
+	both := testhelper.GetTestNote(5, "both")
+
+	after := edited
+	after.Value = "after edit"
+
+	base := entriesFile(kept, removed, edited)
+	ourVersion := entriesFile(kept, edited, both, ours)
+	theirVersion := entriesFile(kept, removed, after, theirs, both)
+
+	expected := entriesFile(kept, after, ours, theirs, both)
+
+	got := new(bytes.Buffer)
+	err := Merge(got, base, ourVersion, theirVersion)
+	testhelper.CompareGotExpected(t, err, got.String(), expected.String())
+}
+
+func Test_MergeInvalid(t *testing.T) {
+	err := Merge(new(bytes.Buffer), strings.NewReader(""), strings.NewReader("note\n"), strings.NewReader(""))
+	if err == nil {
+		t.Fatal("expected an error for an invalid entry")
+	}
+}
+
+func Test_InstallMergeDriver(t *testing.T) {
+	datadir := tmpRepo(t)
+
+	err := ioutil.WriteFile(filepath.Join(datadir, ".gitattributes"), []byte("*.txt text"), 0644)
+	if err != nil {
+		t.Fatal("can not write .gitattributes: ", err)
+	}
+
+	for i := 0; i != 2; i++ {
+		err = InstallMergeDriver(datadir, "lablog")
+		if err != nil {
+			t.Fatal("can not install merge driver: ", err)
+		}
+	}
+
+	attributes, err := ioutil.ReadFile(filepath.Join(datadir, ".gitattributes"))
+	testhelper.CompareGotExpected(t, err, string(attributes), "*.txt text\n"+MergeDriverAttribute+"\n")
+
+	driver, _ := gitConfig(datadir, "merge."+MergeDriverName+".driver")
+	testhelper.CompareGotExpected(t, nil, driver, "lablog git-merge-driver %O %A %B")
+}
@@ -0,0 +1,284 @@
+package vcs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlexanderThaller/dbfiles"
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/juju/errgo"
+)
+
+// QueueFileName is the name of the file in the datadir that contains the
+// entries that were recorded but not committed yet. It is added to the
+// .gitignore file of the datadir so it is never committed itself.
+const QueueFileName = ".lablog-queue"
+
+// queueMutex serializes access to the queue inside of the process. It is not
+// the mutex used by lock as Flush commits while holding the queue.
+var queueMutex sync.Mutex
+
+// QueueEntry is an entry that was queued with Enqueue.
+type QueueEntry struct {
+	Project   data.ProjectName
+	Type      data.EntryType
+	TimeStamp time.Time
+}
+
+// Enqueue adds the entry which already was written to the datadir to the
+// queue of the datadir. The entry is committed with the next Flush.
+func Enqueue(datadir string, project data.ProjectName, entry data.Entry) error {
+	// The queue has to be ignored before it is created so it is not committed
+	// by a commit in between.
+	err := IgnoreQueue(datadir)
+	if err != nil {
+		return errgo.Notef(err, "can not ignore queue")
+	}
+
+	file, unlock, err := lockQueue(datadir)
+	if err != nil {
+		return errgo.Notef(err, "can not lock queue")
+	}
+	defer unlock()
+
+	_, err = file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return errgo.Notef(err, "can not seek to end of queue")
+	}
+
+	err = dbfiles.CSV{}.Write(file, []string{
+		project.String(),
+		entry.Type().String(),
+		entry.GetTimeStamp().Format(data.TimeStampFormat),
+	})
+	if err != nil {
+		return errgo.Notef(err, "can not write entry to queue")
+	}
+
+	return nil
+}
+
+// IgnoreQueue adds the queue to the .gitignore file of the datadir if it is
+// not in there yet.
+func IgnoreQueue(datadir string) error {
+	queueMutex.Lock()
+	defer queueMutex.Unlock()
+
+	err := appendLine(filepath.Join(datadir, ".gitignore"), QueueFileName)
+	if err != nil {
+		return errgo.Notef(err, "can not add queue to .gitignore")
+	}
+
+	return nil
+}
+
+// Queued returns the entries in the queue of the datadir.
+func Queued(datadir string) ([]QueueEntry, error) {
+	if !queueExists(datadir) {
+		return nil, nil
+	}
+
+	file, unlock, err := lockQueue(datadir)
+	if err != nil {
+		return nil, errgo.Notef(err, "can not lock queue")
+	}
+	defer unlock()
+
+	return readQueue(file)
+}
+
+// Flush commits all changes of the datadir with a summary of the queued
+// entries as the message and empties the queue. It returns the message or
+// an empty string if nothing was committed because the queue was empty or
+// its entries were already committed.
+func Flush(datadir string) (string, error) {
+	if !queueExists(datadir) {
+		return "", nil
+	}
+
+	file, unlock, err := lockQueue(datadir)
+	if err != nil {
+		return "", errgo.Notef(err, "can not lock queue")
+	}
+	defer unlock()
+
+	entries, err := readQueue(file)
+	if err != nil {
+		return "", errgo.Notef(err, "can not read queue")
+	}
+
+	if len(entries) == 0 {
+		return "", nil
+	}
+
+	message := Summary(entries)
+
+	committed, err := commitChanged(datadir, message)
+	if err != nil {
+		return "", errgo.Notef(err, "can not commit queued entries")
+	}
+
+	// The queued entries can already be committed by a commit that did not
+	// go through the queue. They are removed from the queue nonetheless.
+	err = file.Truncate(0)
+	if err != nil {
+		return "", errgo.Notef(err, "can not empty queue")
+	}
+
+	if !committed {
+		return "", nil
+	}
+
+	return message, nil
+}
+
+// commitChanged commits all changes of the datadir with the message and
+// returns if a new commit was recorded. Backends record nothing if there are
+// no changes.
+func commitChanged(datadir, message string) (bool, error) {
+	backend, err := Open(datadir)
+	if err != nil {
+		return false, errgo.Notef(err, "can not open vcs backend")
+	}
+
+	before, err := backend.Log(1)
+	if err != nil {
+		return false, errgo.Notef(err, "can not get last commit")
+	}
+
+	err = backend.Commit(message)
+	if err != nil {
+		return false, errgo.Notef(err, "can not commit changes")
+	}
+
+	after, err := backend.Log(1)
+	if err != nil {
+		return false, errgo.Notef(err, "can not get last commit")
+	}
+
+	if len(after) == 0 {
+		return false, nil
+	}
+
+	return len(before) == 0 || before[0].ID != after[0].ID, nil
+}
+
+// Summary returns the commit message for the entries. The first line contains
+// the number of entries and projects, every following line the number of
+// entries of each type of one project:
+//
+//	batch - 3 entries in 2 projects
+//
+//	Test.A - 1 note, 1 todo
+//	Test.B - 1 note
+func Summary(entries []QueueEntry) string {
+	counts := make(map[string]map[data.EntryType]int)
+	for _, entry := range entries {
+		name := entry.Project.String()
+		if counts[name] == nil {
+			counts[name] = make(map[data.EntryType]int)
+		}
+
+		counts[name][entry.Type]++
+	}
+
+	var projects []string
+	for name := range counts {
+		projects = append(projects, name)
+	}
+	sort.Strings(projects)
+
+	message := "batch - " + plural(len(entries), "entry", "entries") + " in " +
+		plural(len(projects), "project", "projects") + "\n"
+
+	for _, name := range projects {
+		var types []string
+		for _, etype := range []data.EntryType{data.EntryTypeNote, data.EntryTypeTodo} {
+			if count := counts[name][etype]; count != 0 {
+				types = append(types, plural(count, etype.String(), etype.String()+"s"))
+			}
+		}
+
+		message += "\n" + name + " - " + strings.Join(types, ", ")
+	}
+
+	return message
+}
+
+func plural(count int, singular, plural string) string {
+	if count == 1 {
+		return "1 " + singular
+	}
+
+	return strconv.Itoa(count) + " " + plural
+}
+
+// queueExists returns false if nothing was queued in the datadir yet. Only
+// Enqueue creates the queue so reading it does not leave a file that is not
+// ignored.
+func queueExists(datadir string) bool {
+	_, err := os.Stat(filepath.Join(datadir, QueueFileName))
+	return err == nil
+}
+
+// lockQueue opens and locks the queue file of the datadir. The returned
+// function unlocks and closes it again.
+func lockQueue(datadir string) (*os.File, func(), error) {
+	queueMutex.Lock()
+
+	file, err := lockFile(filepath.Join(datadir, QueueFileName), LockTimeout)
+	if err != nil {
+		queueMutex.Unlock()
+		return nil, nil, err
+	}
+
+	return file, func() {
+		unlockFile(file)
+		file.Close()
+		queueMutex.Unlock()
+	}, nil
+}
+
+func readQueue(file *os.File) ([]QueueEntry, error) {
+	_, err := file.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, errgo.Notef(err, "can not seek to start of queue")
+	}
+
+	records, err := dbfiles.CSV{}.Read(file)
+	if err != nil {
+		return nil, errgo.Notef(err, "can not parse queue")
+	}
+
+	var entries []QueueEntry
+	for _, record := range records {
+		if len(record) != 3 {
+			return nil, errgo.New("queue entry has " + strconv.Itoa(len(record)) + " fields instead of 3")
+		}
+
+		project, err := data.ParseProjectName(record[0])
+		if err != nil {
+			return nil, errgo.Notef(err, "can not parse project of queue entry")
+		}
+
+		etype, err := data.ParseEntryType(record[1])
+		if err != nil {
+			return nil, errgo.Notef(err, "can not parse type of queue entry")
+		}
+
+		timestamp, err := time.Parse(data.TimeStampFormat, record[2])
+		if err != nil {
+			return nil, errgo.Notef(err, "can not parse timestamp of queue entry")
+		}
+
+		entries = append(entries, QueueEntry{Project: project, Type: etype, TimeStamp: timestamp})
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,211 @@
+package vcs
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+// memoryBackend makes CommitMessage use a memory backend until the returned
+// function is called.
+func memoryBackend() (*Memory, func()) {
+	memory := NewMemory()
+
+	name := BackendName
+	Backends["memory"] = func(string) Backend { return memory }
+	BackendName = "memory"
+
+	return memory, func() {
+		delete(Backends, "memory")
+		BackendName = name
+	}
+}
+
+func Test_Summary(t *testing.T) {
+	entries := []QueueEntry{
+		{Project: data.ProjectName{"Test", "B"}, Type: data.EntryTypeNote},
+		{Project: data.ProjectName{"Test", "A"}, Type: data.EntryTypeTodo},
+		{Project: data.ProjectName{"Test", "A"}, Type: data.EntryTypeNote},
+		{Project: data.ProjectName{"Test", "A"}, Type: data.EntryTypeNote},
+	}
+
+	testhelper.CompareGotExpected(t, nil, Summary(entries), "batch - 4 entries in 2 projects\n\n"+
+		"Test.A - 2 notes, 1 todo\n"+
+		"Test.B - 1 note")
+}
+
+func Test_QueueFlush(t *testing.T) {
+	memory, restore := memoryBackend()
+	defer restore()
+
+	datadir, err := ioutil.TempDir("", "vcs_test_queue")
+	if err != nil {
+		t.Fatal("can not open tmpdir: ", err)
+	}
+
+	message, err := Flush(datadir)
+	testhelper.CompareGotExpected(t, err, message, "")
+
+	note := testhelper.GetTestNote(0, "note")
+	todo := testhelper.GetTestTodo(1, "todo")
+	for _, entry := range []data.Entry{note, todo} {
+		err := Enqueue(datadir, data.ProjectName{"Test"}, entry)
+		if err != nil {
+			t.Fatal("can not queue entry: ", err)
+		}
+	}
+
+	queued, err := Queued(datadir)
+	testhelper.CompareGotExpected(t, err, queued, []QueueEntry{
+		{Project: data.ProjectName{"Test"}, Type: data.EntryTypeNote, TimeStamp: note.TimeStamp},
+		{Project: data.ProjectName{"Test"}, Type: data.EntryTypeTodo, TimeStamp: todo.TimeStamp},
+	})
+
+	gitignore, err := ioutil.ReadFile(filepath.Join(datadir, ".gitignore"))
+	testhelper.CompareGotExpected(t, err, string(gitignore), QueueFileName+"\n")
+
+	message, err = Flush(datadir)
+	testhelper.CompareGotExpected(t, err, message, "batch - 2 entries in 1 project\n\nTest - 1 note, 1 todo")
+
+	queued, err = Queued(datadir)
+	testhelper.CompareGotExpected(t, err, queued, []QueueEntry(nil))
+
+	log, err := memory.Log(0)
+	if err != nil || len(log) != 1 {
+		t.Fatal("expected one commit but got: ", log, err)
+	}
+	testhelper.CompareGotExpected(t, nil, log[0].Message, message)
+}
+
+func Test_QueueFlushCommitted(t *testing.T) {
+	datadir, backend := tmpGoGit(t)
+
+	name := BackendName
+	BackendName = "gogit"
+	defer func() { BackendName = name }()
+
+	note := testhelper.GetTestNote(0, "note")
+	err := Enqueue(datadir, data.ProjectName{"Test"}, note)
+	if err != nil {
+		t.Fatal("can not queue entry: ", err)
+	}
+	writeFile(t, datadir, "Test.csv")
+
+	// A commit that does not go through the queue already records the entry.
+	err = CommitMessage(datadir, "Test - note")
+	if err != nil {
+		t.Fatal("can not commit: ", err)
+	}
+
+	message, err := Flush(datadir)
+	testhelper.CompareGotExpected(t, err, message, "")
+
+	queued, err := Queued(datadir)
+	testhelper.CompareGotExpected(t, err, queued, []QueueEntry(nil))
+
+	log, err := backend.Log(0)
+	if err != nil || len(log) != 1 {
+		t.Fatal("expected one commit but got: ", log, err)
+	}
+	testhelper.CompareGotExpected(t, nil, log[0].Message, "Test - note")
+}
+
+func Test_Batcher(t *testing.T) {
+	memory, restore := memoryBackend()
+	defer restore()
+
+	datadir, err := ioutil.TempDir("", "vcs_test_batcher")
+	if err != nil {
+		t.Fatal("can not open tmpdir: ", err)
+	}
+
+	batcher := NewBatcher(datadir, 50*time.Millisecond)
+	for i := 0; i != 5; i++ {
+		err := batcher.Add(data.ProjectName{"Test"}, testhelper.GetTestNote(i, "note"))
+		if err != nil {
+			t.Fatal("can not add entry: ", err)
+		}
+	}
+
+	log, _ := memory.Log(0)
+	testhelper.CompareGotExpected(t, nil, len(log), 0)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(log) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		log, _ = memory.Log(0)
+	}
+
+	if len(log) != 1 {
+		t.Fatal("expected one commit for all entries but got: ", log)
+	}
+	testhelper.CompareGotExpected(t, nil, log[0].Message, "batch - 5 entries in 1 project\n\nTest - 5 notes")
+}
+
+func Test_BatcherMaxWait(t *testing.T) {
+	memory, restore := memoryBackend()
+	defer restore()
+
+	datadir, err := ioutil.TempDir("", "vcs_test_batcher")
+	if err != nil {
+		t.Fatal("can not open tmpdir: ", err)
+	}
+
+	batcher := NewBatcher(datadir, 100*time.Millisecond)
+	batcher.maxWait = 300 * time.Millisecond
+
+	// The entries come in faster than the delay so only the maximum wait
+	// flushes them.
+	var log []LogEntry
+	deadline := time.Now().Add(5 * time.Second)
+	for i := 0; len(log) == 0 && time.Now().Before(deadline); i++ {
+		err := batcher.Add(data.ProjectName{"Test"}, testhelper.GetTestNote(i, "note"))
+		if err != nil {
+			t.Fatal("can not add entry: ", err)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		log, _ = memory.Log(0)
+	}
+
+	if len(log) == 0 {
+		t.Fatal("expected a commit after the maximum wait")
+	}
+}
+
+func Test_QueueGitClean(t *testing.T) {
+	datadir := tmpRepo(t)
+
+	writeFile(t, datadir, "README")
+	err := CommitMessage(datadir, "init")
+	if err != nil {
+		t.Fatal("can not commit: ", err)
+	}
+
+	message, err := Flush(datadir)
+	testhelper.CompareGotExpected(t, err, message, "")
+
+	status, err := NewGit(datadir).Status()
+	testhelper.CompareGotExpected(t, err, status, []string(nil))
+
+	writeFile(t, datadir, "Test.csv")
+	err = Enqueue(datadir, data.ProjectName{"Test"}, testhelper.GetTestNote(0, "note"))
+	if err != nil {
+		t.Fatal("can not queue entry: ", err)
+	}
+
+	_, err = Flush(datadir)
+	if err != nil {
+		t.Fatal("can not flush queue: ", err)
+	}
+
+	status, err = NewGit(datadir).Status()
+	testhelper.CompareGotExpected(t, err, status, []string(nil))
+
+	files, err := git(datadir, "ls-files")
+	testhelper.CompareGotExpected(t, err, files, ".gitignore\nREADME\nTest.csv\n")
+}
@@ -0,0 +1,249 @@
+package vcs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/AlexanderThaller/dbfiles"
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/juju/errgo"
+)
+
+// SyncResult describes the changes made by Sync.
+type SyncResult struct {
+	// Updated are the projects that were changed on the remote.
+	Updated []data.ProjectName
+	// Merged are the projects which had conflicts that were resolved by
+	// merging the entries of both sides.
+	Merged []data.ProjectName
+}
+
+// SyncError is returned if the sync failed because of the given projects or
+// other files in the repository.
+type SyncError struct {
+	Projects []data.ProjectName
+	Files    []string
+	Err      error
+}
+
+func (err SyncError) Error() string {
+	message := err.Err.Error()
+
+	if len(err.Projects) != 0 {
+		message += ": projects " + strings.Join(data.ProjectNamesToString(err.Projects), ", ")
+	}
+
+	if len(err.Files) != 0 {
+		message += ": files " + strings.Join(err.Files, ", ")
+	}
+
+	return message
+}
+
+// Sync fetches the upstream branch of the repository in the datadir, rebases
+// the local commits onto it and pushes the result. Conflicts in project files
+// are resolved with Merge, all other conflicts abort the rebase. If the
+// branch has no upstream the branch with the same name on origin is used.
+func Sync(datadir string) (SyncResult, error) {
+	var result SyncResult
+
+	unlock, err := lock(datadir)
+	if err != nil {
+		return result, errgo.Notef(err, "can not lock repository")
+	}
+	defer unlock()
+
+	status, err := git(datadir, "status", "--porcelain")
+	if err != nil {
+		return result, errgo.Notef(err, "can not get status of repository")
+	}
+
+	if status != "" {
+		var files []string
+		for _, line := range strings.Split(strings.TrimRight(status, "\n"), "\n") {
+			files = append(files, strings.TrimSpace(line[2:]))
+		}
+
+		projects, other := projectsFromFiles(strings.Join(files, "\n"))
+		return result, SyncError{Projects: projects, Files: other,
+			Err: errgo.New("repository has uncommitted changes")}
+	}
+
+	branch, err := git(datadir, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return result, errgo.Notef(err, "can not get current branch")
+	}
+	branch = strings.TrimSpace(branch)
+
+	remote, tracking := gitConfig(datadir, "branch."+branch+".remote")
+	if !tracking {
+		remote = "origin"
+	}
+
+	upstream := branch
+	if merge, ok := gitConfig(datadir, "branch."+branch+".merge"); ok {
+		upstream = strings.TrimPrefix(merge, "refs/heads/")
+	}
+
+	_, err = git(datadir, "fetch", "-q", remote)
+	if err != nil {
+		return result, errgo.Notef(err, "can not fetch from remote "+remote)
+	}
+
+	ref := "refs/remotes/" + remote + "/" + upstream
+	_, err = git(datadir, "rev-parse", "--verify", "-q", ref)
+	if err == nil {
+		changed, err := git(datadir, "diff", "--name-only", "HEAD..."+ref)
+		if err != nil {
+			return result, errgo.Notef(err, "can not get changes of remote")
+		}
+		result.Updated, _ = projectsFromFiles(changed)
+
+		result.Merged, err = rebase(datadir, ref)
+		if err != nil {
+			return result, errgo.Notef(err, "can not rebase onto "+ref)
+		}
+	}
+
+	args := []string{"push", "-q"}
+	if !tracking {
+		args = append(args, "-u")
+	}
+	args = append(args, remote, "HEAD:refs/heads/"+upstream)
+
+	_, err = git(datadir, args...)
+	if err != nil {
+		return result, errgo.Notef(err, "can not push to remote "+remote)
+	}
+
+	return result, nil
+}
+
+// rebase rebases the current branch onto the ref and resolves conflicts in
+// project files. It returns the projects that were merged. The rebase is
+// aborted if it fails.
+func rebase(datadir, ref string) ([]data.ProjectName, error) {
+	var merged []data.ProjectName
+
+	_, err := git(datadir, "rebase", ref)
+	for err != nil {
+		output, ferr := git(datadir, "diff", "--name-only", "--diff-filter=U")
+		if ferr != nil || output == "" {
+			abortRebase(datadir)
+			return nil, err
+		}
+
+		projects, files := projectsFromFiles(output)
+		if len(files) != 0 {
+			abortRebase(datadir)
+			return nil, SyncError{Projects: projects, Files: files,
+				Err: errgo.New("can not merge conflicts")}
+		}
+
+		for _, project := range projects {
+			merr := resolve(datadir, project)
+			if merr != nil {
+				abortRebase(datadir)
+				return nil, SyncError{Projects: []data.ProjectName{project}, Err: merr}
+			}
+		}
+		merged = append(merged, projects...)
+
+		staged, serr := gitStaged(datadir)
+		if serr != nil {
+			abortRebase(datadir)
+			return nil, serr
+		}
+
+		// The commit is empty if the remote already had all of its entries.
+		if staged {
+			_, err = git(datadir, "-c", "core.editor=true", "rebase", "--continue")
+		} else {
+			_, err = git(datadir, "rebase", "--skip")
+		}
+	}
+
+	return merged, nil
+}
+
+// resolve replaces the conflicting project file with the merged entries of
+// both sides and marks it as resolved.
+func resolve(datadir string, project data.ProjectName) error {
+	path := projectFile(project)
+
+	// Stage 1 is the common base which is missing if both sides added the
+	// project.
+	base, _ := git(datadir, "show", ":1:"+path)
+
+	// Stage 2 is the upstream and stage 3 the local commit that is replayed.
+	ours, err := git(datadir, "show", ":2:"+path)
+	if err != nil {
+		return errgo.Notef(err, "can not get upstream version")
+	}
+
+	theirs, err := git(datadir, "show", ":3:"+path)
+	if err != nil {
+		return errgo.Notef(err, "can not get local version")
+	}
+
+	merged := new(bytes.Buffer)
+	err = Merge(merged, strings.NewReader(base), strings.NewReader(ours), strings.NewReader(theirs))
+	if err != nil {
+		return errgo.Notef(err, "can not merge entries")
+	}
+
+	err = ioutil.WriteFile(filepath.Join(datadir, filepath.FromSlash(path)), merged.Bytes(), 0640)
+	if err != nil {
+		return errgo.Notef(err, "can not write merged project file")
+	}
+
+	_, err = git(datadir, "add", path)
+	if err != nil {
+		return errgo.Notef(err, "can not add merged project file")
+	}
+
+	return nil
+}
+
+func abortRebase(datadir string) {
+	git(datadir, "rebase", "--abort")
+}
+
+// gitConfig returns the value of the config key and false if it is not set.
+func gitConfig(datadir, key string) (string, bool) {
+	value, err := git(datadir, "config", "--get", key)
+	if err != nil {
+		return "", false
+	}
+
+	return strings.TrimSpace(value), true
+}
+
+// projectsFromFiles splits the newline separated paths of the output of git
+// into the names of projects and the paths of other files.
+func projectsFromFiles(output string) ([]data.ProjectName, []string) {
+	extention := "." + dbfiles.CSV{}.Extention()
+
+	var projects []data.ProjectName
+	var files []string
+	for _, path := range strings.Split(output, "\n") {
+		if path == "" {
+			continue
+		}
+
+		if filepath.Ext(path) != extention {
+			files = append(files, path)
+			continue
+		}
+
+		projects = append(projects, data.ProjectName(strings.Split(strings.TrimSuffix(path, extention), "/")))
+	}
+
+	return projects, files
+}
+
+func projectFile(project data.ProjectName) string {
+	return strings.Join(project.Values(), "/") + "." + dbfiles.CSV{}.Extention()
+}
@@ -0,0 +1,173 @@
+package vcs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AlexanderThaller/dbfiles"
+	"github.com/AlexanderThaller/lablog/src/data"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+// tmpRemote returns a bare repository and two clones of it.
+func tmpRemote(t *testing.T) (string, string) {
+	requireGit(t)
+
+	remote, err := ioutil.TempDir("", "vcs_test_remote")
+	if err != nil {
+		t.Fatal("can not open tmpdir: ", err)
+	}
+
+	_, err = git(remote, "init", "-q", "--bare")
+	if err != nil {
+		t.Fatal("can not init bare repository: ", err)
+	}
+
+	first := tmpClone(t, remote)
+	writeFile(t, first, "README")
+	err = CommitMessage(first, "init")
+	if err != nil {
+		t.Fatal("can not commit: ", err)
+	}
+
+	_, err = Sync(first)
+	if err != nil {
+		t.Fatal("can not sync first clone: ", err)
+	}
+
+	return first, tmpClone(t, remote)
+}
+
+func tmpClone(t *testing.T, remote string) string {
+	datadir := tmpRepo(t)
+
+	_, err := git(datadir, "remote", "add", "origin", remote)
+	if err != nil {
+		t.Fatal("can not add remote: ", err)
+	}
+
+	_, err = git(datadir, "fetch", "-q", "origin")
+	if err != nil {
+		t.Fatal("can not fetch remote: ", err)
+	}
+
+	branch, _ := git(datadir, "symbolic-ref", "--short", "HEAD")
+	if _, err := git(datadir, "rev-parse", "-q", "--verify", "origin/"+strings.TrimSpace(branch)); err == nil {
+		_, err = git(datadir, "reset", "-q", "--hard", "origin/"+strings.TrimSpace(branch))
+		if err != nil {
+			t.Fatal("can not reset to remote: ", err)
+		}
+	}
+
+	return datadir
+}
+
+func addEntries(t *testing.T, datadir string, project data.ProjectName, entries ...data.Entry) {
+	path := filepath.Join(datadir, filepath.FromSlash(projectFile(project)))
+
+	content, _ := ioutil.ReadFile(path)
+	buffer := bytes.NewBuffer(content)
+	for _, entry := range entries {
+		dbfiles.CSV{}.Write(buffer, entry.Values())
+	}
+
+	err := ioutil.WriteFile(path, buffer.Bytes(), 0644)
+	if err != nil {
+		t.Fatal("can not write project file: ", err)
+	}
+
+	err = CommitMessage(datadir, project.String())
+	if err != nil {
+		t.Fatal("can not commit: ", err)
+	}
+}
+
+func Test_SyncUnionMerge(t *testing.T) {
+	first, second := tmpRemote(t)
+	project := data.ProjectName{"Test"}
+
+	addEntries(t, first, project, testhelper.GetTestNote(0, "first"), testhelper.GetTestNote(2, "first"))
+	_, err := Sync(first)
+	if err != nil {
+		t.Fatal("can not sync first clone: ", err)
+	}
+
+	addEntries(t, second, project, testhelper.GetTestNote(1, "second"), testhelper.GetTestNote(2, "first"))
+	result, err := Sync(second)
+	testhelper.CompareGotExpected(t, err, result, SyncResult{
+		Updated: []data.ProjectName{project},
+		Merged:  []data.ProjectName{project},
+	})
+
+	expected := new(bytes.Buffer)
+	for _, note := range []data.Note{
+		testhelper.GetTestNote(0, "first"),
+		testhelper.GetTestNote(1, "second"),
+		testhelper.GetTestNote(2, "first"),
+	} {
+		dbfiles.CSV{}.Write(expected, note.Values())
+	}
+
+	_, err = Sync(first)
+	if err != nil {
+		t.Fatal("can not sync first clone again: ", err)
+	}
+
+	for _, datadir := range []string{first, second} {
+		got, err := ioutil.ReadFile(filepath.Join(datadir, projectFile(project)))
+		testhelper.CompareGotExpected(t, err, string(got), expected.String())
+	}
+}
+
+func Test_SyncConflictOtherFile(t *testing.T) {
+	first, second := tmpRemote(t)
+
+	writeFile(t, first, "README.first")
+	err := ioutil.WriteFile(filepath.Join(first, "README"), []byte("first\n"), 0644)
+	if err != nil {
+		t.Fatal("can not write file: ", err)
+	}
+	CommitMessage(first, "first")
+
+	_, err = Sync(first)
+	if err != nil {
+		t.Fatal("can not sync first clone: ", err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(second, "README"), []byte("second\n"), 0644)
+	if err != nil {
+		t.Fatal("can not write file: ", err)
+	}
+	CommitMessage(second, "second")
+
+	_, err = Sync(second)
+	if err == nil {
+		t.Fatal("expected an error for a conflict in a file that is not a project")
+	}
+
+	if !strings.Contains(err.Error(), "files README") {
+		t.Fatal("expected the conflicting file in the error but got: ", err)
+	}
+
+	status, _ := git(second, "status", "--porcelain")
+	if status != "" {
+		t.Fatal("expected the rebase to be aborted but got: ", status)
+	}
+}
+
+func Test_SyncUncommitted(t *testing.T) {
+	first, _ := tmpRemote(t)
+
+	writeFile(t, first, "Test.csv")
+
+	_, err := Sync(first)
+	serr, ok := err.(SyncError)
+	if !ok {
+		t.Fatal("expected a sync error but got: ", err)
+	}
+
+	testhelper.CompareGotExpected(t, nil, serr.Projects, []data.ProjectName{{"Test"}})
+}
@@ -0,0 +1,212 @@
+package web
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/helper"
+	"github.com/AlexanderThaller/lablog/src/store"
+
+	"github.com/AlexanderThaller/httphelper"
+	"github.com/juju/errgo"
+	"github.com/julienschmidt/httprouter"
+)
+
+// APIPrefix is the path under which the current version of the JSON api is
+// served.
+const APIPrefix = "/api/v1"
+
+// maxEntrySize is the maximum size of the body of a posted entry.
+const maxEntrySize = 1 << 20
+
+type apiProject struct {
+	Name data.ProjectName `json:"name"`
+}
+
+func apiProjects(w http.ResponseWriter, r *http.Request, p httprouter.Params) *httphelper.HandlerError {
+	projects, err := dataStore.ListProjects(r.URL.Query().Get("archive") == "true")
+	if err != nil {
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not get list of projects"))
+	}
+
+	out := make([]apiProject, 0)
+	for _, project := range projects.List() {
+		out = append(out, apiProject{Name: project.Name})
+	}
+
+	return writeJSON(w, http.StatusOK, out)
+}
+
+// apiProjectEntries returns the entries of one project. With the type note
+// only the notes and with the type todo only the current state of the todos is
+// returned. The entries can be filtered with since and until like the show
+// command.
+func apiProjectEntries(w http.ResponseWriter, r *http.Request, p httprouter.Params) *httphelper.HandlerError {
+	l := httphelper.NewHandlerLogEntry(r)
+
+	values := r.URL.Query()
+
+	name, err := data.ParseProjectName(p.ByName("project"))
+	if err != nil {
+		return httphelper.NewHandlerError(errgo.Notef(err, "can not parse project name"), http.StatusBadRequest)
+	}
+
+	since, until, herr := apiTimeFilters(values.Get("since"), values.Get("until"))
+	if herr != nil {
+		return herr
+	}
+
+	l.Debug("Project: ", name)
+
+	_, err = os.Stat(store.ProjectPath(dataDir, name))
+	if os.IsNotExist(err) {
+		return httphelper.NewHandlerError(errgo.New("project "+name.String()+" does not exist"), http.StatusNotFound)
+	}
+
+	project, err := dataStore.GetProject(name)
+	if err != nil {
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not get project"))
+	}
+	project = project.Between(since, until)
+
+	out := make(data.Entries, 0)
+	switch etype := values.Get("type"); etype {
+	case "":
+		out = append(out, project.Entries...)
+	case data.EntryTypeNote.String():
+		for _, note := range project.Notes() {
+			out = append(out, note)
+		}
+	case data.EntryTypeTodo.String():
+		for _, todo := range project.Todos() {
+			out = append(out, todo)
+		}
+	default:
+		return httphelper.NewHandlerError(errgo.New("the entry type "+etype+" is not known"), http.StatusBadRequest)
+	}
+
+	return writeJSON(w, http.StatusOK, out)
+}
+
+// apiAddEntry records the entry posted as JSON. The timestamp defaults to now
+// and todos get a generated id when they have none. Todos that are posted as
+// done without an id close the existing todo with the same value like the add
+// todo inactive command.
+func apiAddEntry(w http.ResponseWriter, r *http.Request, p httprouter.Params) *httphelper.HandlerError {
+	name, err := data.ParseProjectName(p.ByName("project"))
+	if err != nil {
+		return httphelper.NewHandlerError(errgo.Notef(err, "can not parse project name"), http.StatusBadRequest)
+	}
+
+	err = name.Validate()
+	if err != nil {
+		return httphelper.NewHandlerError(errgo.Notef(err, "can not use project name"), http.StatusBadRequest)
+	}
+
+	raw, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxEntrySize))
+	if err != nil {
+		return httphelper.NewHandlerError(errgo.Notef(err, "can not read request body"), http.StatusBadRequest)
+	}
+
+	entry, err := data.ParseEntryJSON(raw)
+	if err != nil {
+		return httphelper.NewHandlerError(errgo.Notef(err, "can not parse entry"), http.StatusBadRequest)
+	}
+
+	var value string
+	switch parsed := entry.(type) {
+	case data.Note:
+		if parsed.TimeStamp.IsZero() {
+			parsed.TimeStamp = time.Now()
+		}
+		value, entry = parsed.Value, parsed
+	case data.Todo:
+		if parsed.TimeStamp.IsZero() {
+			parsed.TimeStamp = time.Now()
+		}
+		if parsed.ID == "" {
+			parsed.ID = data.NewTodoID(parsed.TimeStamp, parsed.Value)
+
+			if !parsed.Active {
+				parsed, err = helper.ExistingTodo(dataDir, name, parsed)
+				if err != nil {
+					return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not find existing todo"))
+				}
+			}
+		}
+		value, entry = parsed.Value, parsed
+	}
+
+	if strings.TrimSpace(value) == "" {
+		return httphelper.NewHandlerError(errgo.New("value can not be empty"), http.StatusBadRequest)
+	}
+
+	err = recordEntry(name, entry)
+	if err != nil {
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not record entry"))
+	}
+
+	return writeJSON(w, http.StatusCreated, entry)
+}
+
+func apiDates(w http.ResponseWriter, r *http.Request, p httprouter.Params) *httphelper.HandlerError {
+	values := r.URL.Query()
+
+	since, until, herr := apiTimeFilters(values.Get("since"), values.Get("until"))
+	if herr != nil {
+		return herr
+	}
+
+	var args []string
+	if project := values.Get("project"); project != "" {
+		args = append(args, project)
+	}
+
+	projects, err := helper.ProjectsFromArgs(dataStore, args, values.Get("archive") == "true")
+	if err != nil {
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not get projects"))
+	}
+
+	out := make([]string, 0)
+	out = append(out, helper.Dates(helper.FilterProjects(projects, since, until))...)
+
+	return writeJSON(w, http.StatusOK, out)
+}
+
+func apiTimeFilters(rawSince, rawUntil string) (time.Time, time.Time, *httphelper.HandlerError) {
+	reference := time.Now()
+
+	since, err := helper.ParseTimeFilter(rawSince, reference)
+	if err != nil {
+		return time.Time{}, time.Time{}, httphelper.NewHandlerError(errgo.Notef(err, "can not parse since"), http.StatusBadRequest)
+	}
+
+	until, err := helper.ParseTimeFilter(rawUntil, reference)
+	if err != nil {
+		return time.Time{}, time.Time{}, httphelper.NewHandlerError(errgo.Notef(err, "can not parse until"), http.StatusBadRequest)
+	}
+
+	return since, until, nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, value interface{}) *httphelper.HandlerError {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not encode json"))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_, err = w.Write(raw)
+	if err != nil {
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not write json to responsewriter"))
+	}
+
+	return nil
+}
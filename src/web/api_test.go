@@ -0,0 +1,160 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/helper"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+func postJSON(target, body string) *http.Request {
+	r := httptest.NewRequest("POST", target, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+
+	return r
+}
+
+func Test_APIProjects(t *testing.T) {
+	datadir := tmpServer(t)
+
+	for _, name := range []data.ProjectName{{"Test"}, {data.ArchiveName, "Old"}} {
+		err := helper.RecordEntry(datadir, name, testhelper.GetTestNote(0, "note"), false)
+		if err != nil {
+			t.Fatal("can not record entry: ", err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	herr := apiProjects(w, httptest.NewRequest("GET", APIPrefix+"/projects", nil), nil)
+	if herr != nil {
+		t.Fatal("can not get projects: ", herr.Error)
+	}
+
+	testhelper.CompareGotExpected(t, nil, w.Code, http.StatusOK)
+	testhelper.CompareGotExpected(t, nil, w.Body.String(), `[{"name":"Test"}]`)
+}
+
+func Test_APIProjectEntries(t *testing.T) {
+	datadir := tmpServer(t)
+
+	note := testhelper.GetTestNote(0, "note")
+	todo := testhelper.GetTestTodo(1, "todo")
+	for _, entry := range []data.Entry{note, todo} {
+		err := helper.RecordEntry(datadir, data.ProjectName{"Test"}, entry, false)
+		if err != nil {
+			t.Fatal("can not record entry: ", err)
+		}
+	}
+
+	tests := map[string]struct {
+		project string
+		code    int
+		entries data.Entries
+	}{
+		"":            {"Test", http.StatusOK, data.Entries{note, todo}},
+		"?type=note":  {"Test", http.StatusOK, data.Entries{note}},
+		"?type=todo":  {"Test", http.StatusOK, data.Entries{todo}},
+		"?type=bad":   {"Test", http.StatusBadRequest, nil},
+		"?since=bad":  {"Test", http.StatusBadRequest, nil},
+		"?type=note&": {"Missing", http.StatusNotFound, nil},
+	}
+
+	for query, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", APIPrefix+"/projects/"+test.project+"/entries"+query, nil)
+
+		herr := apiProjectEntries(w, r, projectParams(test.project))
+		if herr != nil {
+			testhelper.CompareGotExpected(t, nil, herr.Code, test.code)
+			continue
+		}
+		testhelper.CompareGotExpected(t, nil, w.Code, test.code)
+
+		expected, err := json.Marshal(test.entries)
+		if err != nil {
+			t.Fatal("can not encode entries: ", err)
+		}
+		testhelper.CompareGotExpected(t, nil, w.Body.String(), string(expected))
+	}
+}
+
+func Test_APIAddEntry(t *testing.T) {
+	tmpServer(t)
+
+	w := httptest.NewRecorder()
+	r := postJSON(APIPrefix+"/projects/Test/entries",
+		`{"type":"note","timestamp":"2010-11-10T23:00:00Z","value":"note"}`)
+
+	herr := apiAddEntry(w, r, projectParams("Test"))
+	if herr != nil {
+		t.Fatal("can not add entry: ", herr.Error)
+	}
+	testhelper.CompareGotExpected(t, nil, w.Code, http.StatusCreated)
+
+	project, err := dataStore.GetProject(data.ProjectName{"Test"})
+	testhelper.CompareGotExpected(t, err, project.Notes(), []data.Note{testhelper.GetTestNote(0, "note")})
+}
+
+func Test_APIAddEntryInvalid(t *testing.T) {
+	tests := map[string]struct {
+		project string
+		body    string
+	}{
+		"empty value":   {"Test", `{"type":"note","value":" "}`},
+		"bad type":      {"Test", `{"type":"other","value":"note"}`},
+		"bad json":      {"Test", `{"type":`},
+		"dots":          {"....", `{"type":"note","value":"note"}`},
+		"git folder":    {".git.x", `{"type":"note","value":"note"}`},
+		"archive":       {".archive.x", `{"type":"note","value":"note"}`},
+		"too large":     {"Test", `{"type":"note","value":"` + strings.Repeat("x", maxEntrySize) + `"}`},
+		"bad timestamp": {"Test", `{"type":"note","timestamp":"yesterday","value":"note"}`},
+	}
+
+	for name, test := range tests {
+		tmpServer(t)
+
+		r := postJSON(APIPrefix+"/projects/Test/entries", test.body)
+		herr := apiAddEntry(httptest.NewRecorder(), r, projectParams(test.project))
+		if herr == nil {
+			t.Error(name, ": expected an error")
+			continue
+		}
+		testhelper.CompareGotExpected(t, nil, herr.Code, http.StatusBadRequest)
+
+		projects, err := dataStore.ListProjects(true)
+		testhelper.CompareGotExpected(t, err, len(projects.List()), 0)
+	}
+}
+
+func Test_APIAddEntryDoneTodo(t *testing.T) {
+	datadir := tmpServer(t)
+
+	todo := testhelper.GetTestTodo(0, "todo")
+	err := helper.RecordEntry(datadir, data.ProjectName{"Test"}, todo, false)
+	if err != nil {
+		t.Fatal("can not record todo: ", err)
+	}
+
+	r := postJSON(APIPrefix+"/projects/Test/entries", `{"type":"todo","value":"todo","active":false}`)
+	herr := apiAddEntry(httptest.NewRecorder(), r, projectParams("Test"))
+	if herr != nil {
+		t.Fatal("can not add todo: ", herr.Error)
+	}
+
+	project, err := dataStore.GetProject(data.ProjectName{"Test"})
+	if err != nil {
+		t.Fatal("can not get project: ", err)
+	}
+
+	todos := project.Todos()
+	if len(todos) != 1 {
+		t.Fatal("expected the existing todo to be closed but got: ", todos)
+	}
+	testhelper.CompareGotExpected(t, nil, todos[0].ID, todo.ID)
+	testhelper.CompareGotExpected(t, nil, todos[0].Active, false)
+}
@@ -1,5 +1,8 @@
 // Code generated by go-bindata.
 // sources:
+// templates/html_formEntry.html
+// templates/html_navigation.html
+// templates/html_pageArchive.html
 // templates/html_pageRoot.html
 // templates/trivago-folder.ico
 // DO NOT EDIT!
@@ -69,7 +72,67 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _templatesHtml_pagerootHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x8c\x51\x31\x53\xf3\x30\x0c\xdd\xf3\x2b\xf4\xf9\x3e\x16\xee\x5a\x97\x8d\x4b\x1d\x2f\xc0\xc6\x41\x07\x16\x46\x35\x56\x9b\x40\x6a\xf7\x6c\x71\xa5\xd7\xeb\x7f\x47\x76\x52\x8e\x81\x81\x49\xc9\x7b\x7a\x4f\x4f\xb2\xf9\x77\xff\x7c\xf7\xf2\xba\x7a\x80\x8e\x77\x83\xad\x4c\x2e\x30\xa0\xdf\x36\x8a\xbc\xca\x00\xa1\x93\xb2\x23\x46\x68\x3b\x8c\x89\xb8\x51\x1f\xbc\x99\xdd\x66\x96\x7b\x1e\xc8\x3e\xe2\x7a\x08\x5b\x98\xc1\x2a\x86\x37\x6a\x39\x19\x3d\x12\x95\x49\x7c\xcc\x95\xa5\x83\xe0\x54\x01\x1c\x7a\xc7\x5d\x7d\xb3\x58\x5c\x2d\xab\x73\x55\xcd\x47\x06\x0b\xe7\xfa\xb4\x1f\xf0\x58\x8b\x5b\xfb\xbe\x14\x80\xe9\x93\x67\x8e\xda\x10\x91\xfb\xe0\x6b\x1f\x3c\x65\x99\xd1\x93\xaf\xd1\x53\xbe\x75\x70\xc7\x9c\xa7\xb8\xb5\x03\xa6\xd4\xa8\xf2\x23\x29\x01\x0c\x8f\x6d\x00\xe5\xdb\x3e\xe1\x8e\x24\x63\x57\x38\xfd\x4d\x1a\x8e\x97\x1e\x67\x0d\x42\x17\x69\xd3\x28\x9d\xba\x70\xd0\xe4\x39\xf6\x94\x94\xbd\x36\x1a\xad\x88\x8a\xe2\x74\x82\x28\xc7\x22\xf8\xbf\x1f\x57\x87\xba\x81\x39\x9c\xcf\x7f\x73\xd3\xa2\xbf\x28\xe7\x39\x94\x28\x95\xfd\x05\xfc\x39\x53\x6a\x9c\x66\x93\x77\x79\x96\x20\x79\xd5\x7c\x8e\xe9\x0e\x7a\x7c\xce\xaf\x00\x00\x00\xff\xff\x57\x1f\x3c\xc1\xdf\x01\x00\x00")
+var _templatesHtml_formentryHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xbc\x90\xc1\x4e\xc3\x30\x0c\x86\xef\x7d\x0a\xcb\x27\xb8\x10\x98\x04\xa7\x24\xd2\x5e\x80\x13\x77\x94\xd5\x9e\x1a\xd4\xd6\x21\x71\x3b\xa6\x69\xef\x8e\x52\x6d\xd2\x18\x20\x6e\x5c\x1d\xe7\xf7\xff\x7d\x96\xe2\x0c\x6d\x1f\x4a\x71\x58\xb8\xd5\x07\xf4\x8d\xed\x56\x10\xc9\xe1\x6b\x20\x42\xbf\x26\xb2\xa6\x5b\xf9\xe6\x7a\x35\xca\xb8\x11\xda\xd7\x0f\x5b\xc9\x03\x84\x65\xe4\xd0\xa4\x2c\x6f\xdc\x6a\x31\x87\x03\xdc\xc1\xf1\x68\x46\x51\x2e\x08\x03\x6b\x27\xe4\x30\x49\x51\xf4\x0d\x80\x55\xfe\xd0\x90\x39\xc0\x18\x06\x76\x38\x87\x7e\x62\x84\x2c\xbb\xe2\xf0\x11\xa1\x95\xbe\x38\x7c\xba\x47\x48\x7d\x68\xb9\x93\x9e\x38\x3b\x7c\x16\xad\x5b\xfc\x3e\xc5\xcc\xe4\xad\x39\xc7\x78\xbb\xc9\x4b\x6e\x1c\xd3\xa4\xa0\xfb\xc4\x0e\xeb\x23\x9e\x0e\x68\x1c\xb8\x68\x18\xd2\x55\xe2\xcb\x79\x0e\x37\xc4\xdb\x30\xf5\x0a\xa3\xec\x6e\xf1\x5b\x58\x99\x36\x43\x54\x84\xa5\xa9\xc3\x35\x11\x54\xb8\xea\xc0\x54\x09\x7f\xbb\x50\x21\xf9\xd1\xc5\x6f\x9d\x4f\x52\xbe\xf6\x15\x92\x0b\x03\xff\x8d\x5c\x19\x2e\x91\x0d\xc5\xd9\x37\xd6\x50\x9c\x7d\xf3\x39\x00\x07\x4f\x3c\xbc\x52\x02\x00\x00")
+
+func templatesHtml_formentryHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesHtml_formentryHtml,
+		"templates/html_formEntry.html",
+	)
+}
+
+func templatesHtml_formentryHtml() (*asset, error) {
+	bytes, err := templatesHtml_formentryHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/html_formEntry.html", size: 594, mode: os.FileMode(436), modTime: time.Unix(1792195530, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesHtml_navigationHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x6c\x8f\xc1\x4a\xc4\x30\x14\x45\xf7\xf9\x8a\x4b\x98\xa5\xb4\x7b\x49\x1f\xb8\x17\x19\xc4\x1f\x78\xb4\x99\x36\x4e\x4d\x43\x52\x8a\x90\xbe\x7f\x97\x4e\x74\x6c\xc5\x5d\xb8\x8f\x73\xcf\x8d\xe9\xdc\x82\x76\xe4\x94\x1a\x1d\x38\x72\x1f\x39\x0c\x9a\x94\x09\xa4\x00\xc3\x18\xa2\xbd\x34\x3a\x67\x54\xaf\xd3\x34\x43\x44\xd3\x39\x4e\xef\xb6\x9d\x93\xa9\x99\xb0\x2a\x20\x67\xb8\x0b\xaa\xa7\xd8\x0e\x6e\xb1\x10\x39\x70\xbf\xb1\xa6\xef\x77\x21\x73\x86\xf5\x1d\x44\x4a\x45\x64\xdf\x5b\x9c\x9c\xef\xec\xe7\x03\x4e\xa3\xf3\x57\x3c\x36\xa8\x9e\x9d\xbf\x26\x88\x14\x4b\xb9\x43\x04\x2b\xee\x05\x7b\xdf\x0d\xac\xce\x3c\x0f\xb7\xb1\xf7\xe4\x85\x3f\xb6\x0d\x9b\xfa\x8f\x78\xdb\xfe\x36\xf5\xfd\x68\x7f\xb0\xf5\xf8\xf3\xe3\x51\xd3\x2e\xfb\xb7\xd5\xd4\x81\x94\xa9\x3b\xb7\x90\xfa\x1a\x00\x37\x54\x54\x08\x61\x01\x00\x00")
+
+func templatesHtml_navigationHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesHtml_navigationHtml,
+		"templates/html_navigation.html",
+	)
+}
+
+func templatesHtml_navigationHtml() (*asset, error) {
+	bytes, err := templatesHtml_navigationHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/html_navigation.html", size: 353, mode: os.FileMode(436), modTime: time.Unix(1792196123, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesHtml_pagearchiveHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x54\x50\x3f\xef\xd3\x30\x10\xdd\xfd\x29\x0e\xeb\xc7\xd8\xb8\x6c\x28\xbd\x58\x42\xc0\x86\x4a\x07\x18\x18\x2f\xf1\x35\x0e\x38\x4e\xe5\x1c\x85\xca\xca\x77\x47\x4e\x42\xa5\xdf\x64\xdf\x7b\x7a\x7f\xee\xf0\xcd\xa7\xaf\x1f\xbf\xfd\xb8\x7c\x06\x2f\x63\xb0\x0a\xcb\x03\x81\x62\xdf\x68\x8e\xba\x00\x4c\xce\x2a\x1c\x59\x08\x3a\x4f\x69\x66\x69\xf4\x6f\xb9\x1e\xde\x17\x56\x06\x09\x6c\xbf\x50\x1b\xa6\x1e\x0e\xf0\x21\x75\x7e\xb8\x33\x9a\x0d\x57\x38\xcb\x23\xb0\x55\x42\x6d\x60\xc8\x0a\xe0\xcf\xe0\xc4\xd7\xef\x8e\xc7\xb7\x27\xb5\x28\x55\x6d\x0c\xad\x9c\x1b\xe6\x5b\xa0\x47\xdd\x86\xa9\xfb\x75\x52\x00\xc2\x7f\xe5\xe0\xb8\x9b\x12\xc9\x30\xc5\x3a\x4e\x91\x8b\x0c\xcd\xee\x8b\x66\xaf\xd7\x4e\xee\x61\x55\xce\x20\x3c\xde\x02\x09\x83\x8e\x74\x1f\xfa\x55\xa7\xa1\x3a\x3f\x07\x58\x16\x85\x5b\x6a\x17\x68\x9e\x1b\xbd\x0e\xda\x2a\x00\x94\xcd\x0e\x60\xfd\xdb\x33\x8d\x65\x17\x5f\x10\x34\x4f\x32\x67\x48\x14\x7b\x86\x97\x5b\x9a\x7e\x72\x27\x50\x37\x50\x5d\xb6\xff\x5c\x02\x8a\x3c\xfd\xf7\x71\x16\x09\x7c\xe2\x6b\xa3\x73\x86\x97\xea\x42\xe2\xe7\x6a\x3f\xd5\xae\x7a\x5a\x55\x25\x13\x96\x45\xdb\x9c\x5f\x83\xd5\xf7\x48\x9b\xc6\xc1\xb2\xa0\x21\x8b\x46\xdc\x5e\x2d\xed\xbd\x38\x16\x52\xa1\x59\x97\xb2\x0a\xcd\x76\x19\x34\x5e\xc6\x60\xd5\xbf\x01\x00\x70\x97\xcc\x7a\xf0\x01\x00\x00")
+
+func templatesHtml_pagearchiveHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesHtml_pagearchiveHtml,
+		"templates/html_pageArchive.html",
+	)
+}
+
+func templatesHtml_pagearchiveHtml() (*asset, error) {
+	bytes, err := templatesHtml_pagearchiveHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/html_pageArchive.html", size: 496, mode: os.FileMode(436), modTime: time.Unix(1792196123, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesHtml_pagerootHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x8c\x52\xc1\x8e\xd3\x30\x10\xbd\xfb\x2b\x06\x6b\xb9\x20\x35\x5e\x6e\xa8\xeb\xf8\x02\x1c\x90\xd0\x52\xb1\x5c\x38\x4e\x92\x69\x1c\xd6\xb1\x83\x3d\xdd\x6d\x15\xf5\xdf\x91\xe3\x6d\xd5\x02\x42\x9c\x12\xcf\x9b\xf7\x66\xe6\xcd\xe8\x57\x1f\xbe\xbc\xff\xf6\x7d\xf3\x11\x2c\x8f\xce\x08\x9d\x3f\xe0\xd0\xf7\xb5\x24\x2f\x73\x80\xb0\x33\x42\x8f\xc4\x08\xad\xc5\x98\x88\x6b\xb9\xe3\xed\xea\x5d\x46\x79\x60\x47\xe6\x33\x36\x2e\xf4\xb0\x82\x4d\x0c\x3f\xa8\xe5\xa4\x55\x01\x84\x4e\x7c\x70\x64\x04\x63\xe3\x08\x66\x01\xf0\x3c\x74\x6c\xd7\x6f\x6f\x6f\x5f\xdf\x89\xa3\x10\x55\x41\x70\xc1\xba\x21\x4d\x0e\x0f\xeb\xc6\x85\xf6\xf1\x4e\x00\x30\xed\x79\xd5\x51\x1b\x22\xf2\x10\xfc\xda\x07\x4f\x99\xa6\xd5\x8b\xae\x56\x2f\xfd\x35\xa1\x3b\x18\x31\xcf\xc0\x34\x4e\x0e\x99\x40\x7a\x7c\x1a\xfa\x85\x27\xa1\xba\x3f\x3f\xe0\x78\xcc\x79\xc3\x16\xaa\x0d\xb2\x4d\xd5\x03\x61\x6c\x6d\x0e\xeb\x6d\x88\x23\x60\x9b\x39\xb5\x9c\xe7\x3f\x32\x24\x8c\xc4\x36\x74\xb5\xec\x89\xa5\x11\x00\x7a\xf0\xd3\x8e\x81\x0f\x13\xd5\x32\xb7\x2b\xc1\xe3\x48\xb5\xfc\x29\x61\x72\xd8\x92\x0d\xae\xa3\x58\xcb\xa2\x51\x38\x0e\x1b\x72\xe6\x8a\xda\x5a\x6a\x1f\x9b\xb0\x3f\xd1\x23\xf5\xb4\x97\xf0\x84\x6e\x97\x85\xe3\x8e\xa4\x81\xaf\x39\xa8\x55\xa1\xff\xa7\xd0\xd0\xfb\x10\xa9\xc5\x44\xbf\xab\x7d\x5a\x10\xc8\xd0\xa5\xe6\xa5\x58\xda\x35\xe3\xc0\x67\xe2\x79\x06\xad\xb2\x53\x8b\xdf\xe4\xbb\xc5\xba\xb2\xc7\xd6\x61\x4a\xb5\x5c\x1e\x65\x56\x2e\x0b\x02\x58\xfe\xcd\x3d\x8e\xa4\x15\xdb\x1c\xd1\xea\x0c\x6a\x8e\xa7\x9c\xce\x68\x04\x1b\x69\x7b\xb5\x01\x1b\x9e\x41\x92\xe7\x38\x50\x92\x20\x25\x6c\xd1\x25\xca\x2b\x31\x6f\xb4\x42\xa3\x15\x2f\x42\xf3\x0c\x11\x7d\x4f\x70\x33\x95\x5b\x84\x75\x0d\xd5\xe9\x2e\x73\xa7\xff\x28\x76\xf3\xf7\x6a\x27\xa9\x2a\x37\x5f\x3d\x70\x1c\x7c\x7f\x51\x7f\x9e\xaf\x33\xe0\x78\xbc\xec\x48\xab\x32\xdb\x85\x57\x6a\xf1\xc7\x08\xad\xca\xd9\x6a\x65\x79\x74\x46\xfc\x1a\x00\x55\xf5\x45\xec\x8e\x03\x00\x00")
 
 func templatesHtml_pagerootHtmlBytes() ([]byte, error) {
 	return bindataRead(
@@ -84,7 +147,7 @@ func templatesHtml_pagerootHtml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "templates/html_pageRoot.html", size: 479, mode: os.FileMode(436), modTime: time.Unix(1459277752, 0)}
+	info := bindataFileInfo{name: "templates/html_pageRoot.html", size: 910, mode: os.FileMode(436), modTime: time.Unix(1792196123, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -161,6 +224,9 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
+	"templates/html_formEntry.html": templatesHtml_formentryHtml,
+	"templates/html_navigation.html": templatesHtml_navigationHtml,
+	"templates/html_pageArchive.html": templatesHtml_pagearchiveHtml,
 	"templates/html_pageRoot.html": templatesHtml_pagerootHtml,
 	"templates/trivago-folder.ico": templatesTrivagoFolderIco,
 }
@@ -207,6 +273,9 @@ type bintree struct {
 
 var _bintree = &bintree{nil, map[string]*bintree{
 	"templates": &bintree{nil, map[string]*bintree{
+		"html_formEntry.html": &bintree{templatesHtml_formentryHtml, map[string]*bintree{}},
+		"html_navigation.html": &bintree{templatesHtml_navigationHtml, map[string]*bintree{}},
+		"html_pageArchive.html": &bintree{templatesHtml_pagearchiveHtml, map[string]*bintree{}},
 		"html_pageRoot.html": &bintree{templatesHtml_pagerootHtml, map[string]*bintree{}},
 		"trivago-folder.ico": &bintree{templatesTrivagoFolderIco, map[string]*bintree{}},
 	}},
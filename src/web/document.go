@@ -0,0 +1,83 @@
+package web
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"html/template"
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/AlexanderThaller/lablog/src/asciidoc"
+	"github.com/juju/errgo"
+)
+
+// document is the asciidoc document of a page. The html of the templates is
+// not written into the document as the renderer escapes passthrough blocks.
+// The document only contains a placeholder paragraph for it and the html is
+// inserted into the rendered page instead.
+type document struct {
+	bytes.Buffer
+
+	token string
+	html  []string
+}
+
+// newDocument returns an empty document. The placeholders start with a
+// random token so they are not found in notes by accident. A note that
+// contains a placeholder nonetheless only repeats the html of the templates.
+func newDocument() *document {
+	raw := make([]byte, 8)
+	rand.Read(raw)
+
+	return &document{token: "lablog" + hex.EncodeToString(raw)}
+}
+
+// executeTemplate executes the template and writes a placeholder for the
+// resulting html into the document.
+func (doc *document) executeTemplate(tmpl *template.Template, values interface{}) error {
+	buffer := new(bytes.Buffer)
+
+	err := tmpl.Execute(buffer, values)
+	if err != nil {
+		return err
+	}
+
+	io.WriteString(doc, "\n\n"+doc.placeholder(len(doc.html))+"\n\n")
+	doc.html = append(doc.html, buffer.String())
+
+	return nil
+}
+
+func (doc *document) placeholder(index int) string {
+	return doc.token + "x" + strconv.Itoa(index)
+}
+
+// render renders the document with the renderer and writes the page with the
+// html of the templates to the writer.
+func (doc *document) render(render asciidoc.Renderer, writer io.Writer) error {
+	rendered := new(bytes.Buffer)
+
+	err := render(&doc.Buffer, rendered)
+	if err != nil {
+		return err
+	}
+
+	// The renderers wrap the placeholder into a paragraph which is replaced
+	// as a whole.
+	page := rendered.String()
+	for index, html := range doc.html {
+		placeholder := regexp.QuoteMeta("<p>" + doc.placeholder(index) + "</p>")
+		regex := regexp.MustCompile(`<div class="paragraph">\s*` + placeholder + `\s*</div>\n?|` + placeholder)
+
+		page = regex.ReplaceAllLiteralString(page, html)
+	}
+
+	_, err = io.WriteString(writer, page)
+	if err != nil {
+		return errgo.Notef(err, "can not write page")
+	}
+
+	return nil
+}
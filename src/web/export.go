@@ -0,0 +1,186 @@
+package web
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/AlexanderThaller/lablog/src/asciidoc"
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/formatting"
+	"github.com/AlexanderThaller/lablog/src/helper"
+	"github.com/juju/errgo"
+)
+
+// Export writes the pages of the webserver for all projects as a static site
+// to the outdir. There is a page for every view of the show page for all
+// projects and for every project and project subtree, a page for every date
+// and an index page. Archived projects are only exported if archive is true.
+func Export(datadir, outdir string, archive bool, renderer string) error {
+	render, err := asciidoc.Backend(renderer)
+	if err != nil {
+		return errgo.Notef(err, "can not get asciidoc renderer")
+	}
+
+	store, err := helper.DefaultStore(datadir)
+	if err != nil {
+		return errgo.Notef(err, "can not get data store")
+	}
+
+	projects, err := helper.ProjectsFromArgs(store, nil, archive)
+	if err != nil {
+		return errgo.Notef(err, "can not get projects")
+	}
+
+	err = exportPage(outdir, "index.html", func(writer io.Writer) error {
+		var active []data.Project
+		for _, project := range projects.List() {
+			if !project.Name.IsArchived() {
+				active = append(active, project)
+			}
+		}
+
+		return executePage(writer, "templates/html_pageRoot.html", newExportPaths("index.html", archive), archive, active)
+	})
+	if err != nil {
+		return err
+	}
+
+	if archive {
+		err = exportPage(outdir, "archive.html", func(writer io.Writer) error {
+			return executePage(writer, "templates/html_pageArchive.html", newExportPaths("archive.html", archive),
+				archive, archivedProjects(projects))
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, etype := range showTypeNames {
+		err = exportShow(outdir, render, archive, etype, "", &projects)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range projectSubtrees(projects) {
+			subtree := data.NewProjects()
+			for _, project := range projects.List() {
+				if project.Name.HasPrefix(name) {
+					subtree.Add(project)
+				}
+			}
+
+			err = exportShow(outdir, render, archive, etype, name.String(), &subtree)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, date := range helper.Dates(projects) {
+		since, err := time.ParseInLocation(helper.DateFormat, date, time.Local)
+		if err != nil {
+			return errgo.Notef(err, "can not parse date "+date)
+		}
+
+		filtered := helper.FilterProjects(projects, since, since.AddDate(0, 0, 1))
+
+		file := exportFile("date", date)
+		err = exportRendered(outdir, file, render, func(writer *document) error {
+			return writeEntries(writer, newExportPaths(file, archive), date, "", archive, &filtered, formatting.Project)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func exportShow(outdir string, render asciidoc.Renderer, archive bool, etype, project string, projects *data.Projects) error {
+	file := exportFile(etype, project)
+
+	return exportRendered(outdir, file, render, func(writer *document) error {
+		return writeShow(writer, newExportPaths(file, archive), etype, project, archive, projects)
+	})
+}
+
+// exportRendered renders the asciidoc document written by the given function
+// to the file.
+func exportRendered(outdir, file string, render asciidoc.Renderer, write func(*document) error) error {
+	return exportPage(outdir, file, func(writer io.Writer) error {
+		doc := newDocument()
+
+		err := write(doc)
+		if err != nil {
+			return err
+		}
+
+		return doc.render(render, writer)
+	})
+}
+
+func exportPage(outdir, file string, write func(io.Writer) error) error {
+	path := filepath.Join(outdir, filepath.FromSlash(file))
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return errgo.Notef(err, "can not create folder for page "+file)
+	}
+
+	buffer := new(bytes.Buffer)
+	err = write(buffer)
+	if err != nil {
+		return errgo.Notef(err, "can not write page "+file)
+	}
+
+	err = writeFile(path, buffer.Bytes())
+	if err != nil {
+		return errgo.Notef(err, "can not write page "+file)
+	}
+
+	return nil
+}
+
+func writeFile(path string, content []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(content)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// projectSubtrees returns the names of all projects and of all their parents
+// sorted by name.
+func projectSubtrees(projects data.Projects) []data.ProjectName {
+	seen := make(map[string]data.ProjectName)
+	for _, project := range projects.List() {
+		for i := 1; i <= len(project.Name); i++ {
+			name := project.Name[:i]
+			seen[name.String()] = name
+		}
+	}
+
+	var keys []string
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var names []data.ProjectName
+	for _, key := range keys {
+		names = append(names, seen[key])
+	}
+
+	return names
+}
@@ -0,0 +1,142 @@
+package web
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/helper"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+)
+
+var regexHref = regexp.MustCompile(`href="([^"#]+)"`)
+
+// tmpExport records a note for each project in a new datadir and exports it
+// to a new outdir. It returns the outdir and the slash separated paths of all
+// exported files.
+func tmpExport(t *testing.T, archive bool, names ...data.ProjectName) (string, map[string]bool) {
+	datadir, err := ioutil.TempDir("", "web_test_datadir")
+	if err != nil {
+		t.Fatal("can not open tmpdir: ", err)
+	}
+
+	outdir, err := ioutil.TempDir("", "web_test_export")
+	if err != nil {
+		t.Fatal("can not open tmpdir: ", err)
+	}
+
+	for _, name := range names {
+		err := helper.RecordEntry(datadir, name, testhelper.GetTestNote(0, "note of "+name.String()), false)
+		if err != nil {
+			t.Fatal("can not record entry: ", err)
+		}
+	}
+
+	err = Export(datadir, outdir, archive, "builtin")
+	if err != nil {
+		t.Fatal("can not export: ", err)
+	}
+
+	files := make(map[string]bool)
+	err = filepath.Walk(outdir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		relpath, err := filepath.Rel(outdir, path)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(relpath)] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatal("can not list exported files: ", err)
+	}
+
+	return outdir, files
+}
+
+// checkExportLinks checks that no exported file is hidden and that all links
+// lead to exported files. It returns the content of all files.
+func checkExportLinks(t *testing.T, outdir string, files map[string]bool) map[string]string {
+	contents := make(map[string]string)
+	for file := range files {
+		for _, component := range strings.Split(file, "/") {
+			if strings.HasPrefix(component, ".") {
+				t.Error("exported file is hidden: ", file)
+			}
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join(outdir, filepath.FromSlash(file)))
+		if err != nil {
+			t.Fatal("can not read exported file: ", err)
+		}
+		contents[file] = string(content)
+
+		for _, match := range regexHref.FindAllStringSubmatch(string(content), -1) {
+			target := filepath.ToSlash(filepath.Join(filepath.Dir(file), match[1]))
+			if !files[target] {
+				t.Error("link from ", file, " to ", match[1], " does not lead to an exported file")
+			}
+		}
+	}
+
+	return contents
+}
+
+func Test_Export(t *testing.T) {
+	outdir, files := tmpExport(t, true, data.ProjectName{"Work", "A"}, data.ProjectName{data.ArchiveName, "Old"})
+
+	for _, file := range []string{
+		"index.html",
+		"archive.html",
+		"entries.html",
+		"entries/Work.html",
+		"entries/Work.A.html",
+		"entries/archive/index.html",
+		"entries/archive/Old.html",
+		"todos/archive/Old.html",
+	} {
+		if !files[file] {
+			t.Error("expected exported file ", file)
+		}
+	}
+
+	checkExportLinks(t, outdir, files)
+}
+
+func Test_ExportWithoutArchive(t *testing.T) {
+	outdir, files := tmpExport(t, false, data.ProjectName{"Work", "A"}, data.ProjectName{data.ArchiveName, "Old"})
+
+	expected := map[string]bool{
+		"index.html":           true,
+		"date/2010-11-10.html": true,
+	}
+	for _, etype := range showTypeNames {
+		for _, name := range []string{"", "Work", "Work.A"} {
+			expected[exportFile(etype, name)] = true
+		}
+	}
+
+	testhelper.CompareGotExpected(t, nil, files, expected)
+
+	contents := checkExportLinks(t, outdir, files)
+	for file, content := range contents {
+		if strings.Contains(content, "archive") {
+			t.Error("expected no archive in ", file, " but got: ", content)
+		}
+	}
+
+	// The pages link to the other views of the same projects.
+	for _, link := range []string{`href="../notes/Work.A.html"`, `href="../index.html"`, `href="../date/2010-11-10.html"`} {
+		if !strings.Contains(contents["entries/Work.A.html"]+contents["dates/Work.A.html"], link) {
+			t.Error("expected a link ", link, " in the pages of Work.A")
+		}
+	}
+}
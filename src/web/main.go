@@ -1,26 +1,54 @@
 package web
 
 import (
-	"bytes"
 	"html/template"
-	"io"
 	"net/http"
-	"os/exec"
+	"net/url"
+	"time"
 
 	"github.com/AlexanderThaller/httphelper"
+	"github.com/AlexanderThaller/lablog/src/asciidoc"
+	"github.com/AlexanderThaller/lablog/src/data"
 	"github.com/AlexanderThaller/lablog/src/helper"
 	"github.com/AlexanderThaller/lablog/src/store"
+	"github.com/AlexanderThaller/lablog/src/vcs"
 	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errgo"
 	"github.com/julienschmidt/httprouter"
 )
 
 var (
-	dataStore store.Store
+	dataStore  store.Store
+	dataDir    string
+	autoCommit bool
+	batcher    *vcs.Batcher
+	render     asciidoc.Renderer
 )
 
-func Listen(datadir, binding string, loglevel log.Level) error {
+// Listen serves the datadir on the binding. If commit is true added entries
+// are committed together once no entry was added for the commit delay or at
+// the latest after vcs.MaxWaitDelays commit delays. With a commit delay of 0
+// every entry is committed on its own.
+func Listen(datadir, binding string, loglevel log.Level, commit bool, commitDelay time.Duration, renderer string) error {
+	dataDir = datadir
+	autoCommit = commit
+
+	if commit && commitDelay > 0 {
+		batcher = vcs.NewBatcher(datadir, commitDelay)
+
+		// Commit entries that were queued when the server stopped last time.
+		_, err := batcher.Flush()
+		if err != nil {
+			return errgo.Notef(err, "can not commit queued entries")
+		}
+	}
+
 	var err error
+	render, err = asciidoc.Backend(renderer)
+	if err != nil {
+		return errgo.Notef(err, "can not get asciidoc renderer")
+	}
+
 	dataStore, err = helper.DefaultStore(datadir)
 	if err != nil {
 		return errgo.Notef(err, "can not get data store")
@@ -40,6 +68,23 @@ func Listen(datadir, binding string, loglevel log.Level) error {
 	router.GET("/show/:type/", httphelper.HandlerLoggerRouter(pageShow))
 	router.GET("/show/:type/:project", httphelper.HandlerLoggerRouter(pageShow))
 
+	// Add
+	router.POST("/projects/:project/notes", httphelper.HandlerLoggerRouter(sameOrigin(pageAddNote)))
+	router.POST("/projects/:project/todos", httphelper.HandlerLoggerRouter(sameOrigin(pageAddTodo)))
+
+	// API
+	router.GET(APIPrefix+"/projects", httphelper.HandlerLoggerRouter(apiProjects))
+	router.GET(APIPrefix+"/projects/:project/entries", httphelper.HandlerLoggerRouter(apiProjectEntries))
+	router.POST(APIPrefix+"/projects/:project/entries", httphelper.HandlerLoggerRouter(sameOrigin(apiAddEntry)))
+	router.GET(APIPrefix+"/dates", httphelper.HandlerLoggerRouter(apiDates))
+
+	// Archive
+	router.GET("/archive/", httphelper.HandlerLoggerRouter(pageArchive))
+	router.GET("/archive/:project", httphelper.HandlerLoggerRouter(pageArchiveShow))
+
+	// Search
+	router.GET("/search", httphelper.HandlerLoggerRouter(pageSearch))
+
 	log.Info("Listening on ", binding)
 	err = http.ListenAndServe(binding, router)
 	if err != nil {
@@ -49,6 +94,51 @@ func Listen(datadir, binding string, loglevel log.Level) error {
 	return nil
 }
 
+// recordEntry records the entry and commits it right away or queues it in the
+// batcher.
+func recordEntry(project data.ProjectName, entry data.Entry) error {
+	if batcher == nil {
+		return helper.RecordEntry(dataDir, project, entry, autoCommit)
+	}
+
+	err := helper.RecordEntry(dataDir, project, entry, false)
+	if err != nil {
+		return err
+	}
+
+	err = batcher.Add(project, entry)
+	if err != nil {
+		return errgo.Notef(err, "can not queue entry")
+	}
+
+	return nil
+}
+
+// sameOrigin rejects requests that a browser sent from a page of another
+// origin so other sites can not add entries through the browser of a user.
+// Requests without Origin and Referer headers are not sent by a browser page
+// and are allowed.
+func sameOrigin(fn httphelper.Handler) httphelper.Handler {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) *httphelper.HandlerError {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			origin = r.Header.Get("Referer")
+		}
+
+		if origin != "" {
+			parsed, err := url.Parse(origin)
+			if err != nil || parsed.Host != r.Host {
+				return httphelper.NewHandlerError(errgo.New("request from origin "+origin+" is not allowed"),
+					http.StatusForbidden)
+			}
+		}
+
+		return fn(w, r, p)
+	}
+}
+
+const navigationAsset = "templates/html_navigation.html"
+
 func getAssetTemplate(asset string) (*template.Template, error) {
 	rawtmpl, err := Asset(asset)
 	if err != nil {
@@ -60,22 +150,16 @@ func getAssetTemplate(asset string) (*template.Template, error) {
 		return nil, errgo.Notef(err, "can not parse template for asset: "+asset)
 	}
 
-	return tmpl, nil
-}
-
-func asciiDoctor(reader io.Reader, writer io.Writer) error {
-	stderr := new(bytes.Buffer)
-
-	command := exec.Command("asciidoctor", "-")
-	command.Stdin = reader
-	command.Stdout = writer
-	command.Stderr = stderr
+	// Every page can include the navigation between the pages.
+	rawnavigation, err := Asset(navigationAsset)
+	if err != nil {
+		return nil, errgo.Notef(err, "can not get asset: "+navigationAsset)
+	}
 
-	err := command.Run()
+	_, err = tmpl.New("navigation").Parse(string(rawnavigation))
 	if err != nil {
-		return errgo.Notef(errgo.Notef(err, "can not run asciidoctor"),
-			stderr.String())
+		return nil, errgo.Notef(err, "can not parse template for asset: "+navigationAsset)
 	}
 
-	return nil
+	return tmpl, nil
 }
@@ -3,9 +3,14 @@ package web
 import (
 	"bytes"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/AlexanderThaller/lablog/src/data"
 	"github.com/AlexanderThaller/lablog/src/formatting"
 	"github.com/AlexanderThaller/lablog/src/helper"
+	"github.com/AlexanderThaller/lablog/src/index"
+	"github.com/AlexanderThaller/lablog/src/search"
 
 	"github.com/AlexanderThaller/httphelper"
 	"github.com/juju/errgo"
@@ -18,14 +23,9 @@ func pageRoot(w http.ResponseWriter, r *http.Request, p httprouter.Params) *http
 		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not get list of projects"))
 	}
 
-	tmpl, err := getAssetTemplate("templates/html_pageRoot.html")
+	err = executePage(w, "templates/html_pageRoot.html", serverPaths{}, false, projects.List())
 	if err != nil {
-		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not execute pageRoot template with project list"))
-	}
-
-	err = tmpl.Execute(w, projects.List())
-	if err != nil {
-		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not execute template pageRoot"))
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not write pageRoot"))
 	}
 
 	return nil
@@ -36,26 +36,237 @@ func pageShow(w http.ResponseWriter, r *http.Request, p httprouter.Params) *http
 
 	etype := p.ByName("type")
 	project := p.ByName("project")
+	archive := r.URL.Query().Get("archive") == "true"
 
 	l.Debug("Type: ", etype)
 	l.Debug("Project: ", project)
+	l.Debug("Archive: ", archive)
+
+	var args []string
+	if project != "" {
+		args = append(args, project)
+	}
+
+	projects, err := helper.ProjectsFromArgs(dataStore, args, archive)
+	if err != nil {
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not get projects"))
+	}
+
+	doc := newDocument()
+	err = writeShow(doc, serverPaths{}, etype, project, archive, &projects)
+	if err == errUnknownShowType {
+		return httphelper.NewHandlerError(errgo.New("the show type "+etype+" is not known"), http.StatusNotFound)
+	}
+	if err != nil {
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not write show page"))
+	}
+
+	if project != "" {
+		err = formEntry(doc, project)
+		if err != nil {
+			return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not write entry form"))
+		}
+	}
+
+	err = doc.render(render, w)
+	if err != nil {
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not render entries"))
+	}
+
+	return nil
+}
+
+func pageAddNote(w http.ResponseWriter, r *http.Request, p httprouter.Params) *httphelper.HandlerError {
+	project, timestamp, value, herr := formEntryValues(r, p)
+	if herr != nil {
+		return herr
+	}
+
+	note := data.Note{
+		TimeStamp: timestamp,
+		Value:     value,
+	}
+
+	return addEntry(w, r, project, note)
+}
+
+func pageAddTodo(w http.ResponseWriter, r *http.Request, p httprouter.Params) *httphelper.HandlerError {
+	project, timestamp, value, herr := formEntryValues(r, p)
+	if herr != nil {
+		return herr
+	}
+
+	todo := data.Todo{
+		Active:    r.FormValue("done") != "true",
+		TimeStamp: timestamp,
+		Value:     value,
+		ID:        data.NewTodoID(timestamp, value),
+	}
+
+	// Marking a todo as done by its value closes the existing todo.
+	if !todo.Active {
+		var err error
+		todo, err = helper.ExistingTodo(dataDir, project, todo)
+		if err != nil {
+			return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not find existing todo"))
+		}
+	}
+
+	return addEntry(w, r, project, todo)
+}
+
+// formEntryValues reads the project from the route and the value and the
+// optional timestamp from the posted form. Invalid input is returned as a bad
+// request.
+func formEntryValues(r *http.Request, p httprouter.Params) (data.ProjectName, time.Time, string, *httphelper.HandlerError) {
+	l := httphelper.NewHandlerLogEntry(r)
+
+	err := r.ParseForm()
+	if err != nil {
+		return data.ProjectName{}, time.Time{}, "", httphelper.NewHandlerError(
+			errgo.Notef(err, "can not parse form"), http.StatusBadRequest)
+	}
+
+	project, err := data.ParseProjectName(p.ByName("project"))
+	if err != nil {
+		return data.ProjectName{}, time.Time{}, "", httphelper.NewHandlerError(
+			errgo.Notef(err, "can not parse project name"), http.StatusBadRequest)
+	}
+
+	err = project.Validate()
+	if err != nil {
+		return data.ProjectName{}, time.Time{}, "", httphelper.NewHandlerError(
+			errgo.Notef(err, "can not use project name"), http.StatusBadRequest)
+	}
+
+	value := strings.TrimSpace(r.PostFormValue("value"))
+	if value == "" {
+		return data.ProjectName{}, time.Time{}, "", httphelper.NewHandlerError(
+			errgo.New("value can not be empty"), http.StatusBadRequest)
+	}
+
+	timestamp := time.Now()
+	raw := strings.TrimSpace(r.PostFormValue("timestamp"))
+	if raw == "" {
+		raw = timestamp.String()
+	}
+
+	timestamp, err = helper.DefaultOrRawTimestamp(timestamp, raw)
+	if err != nil {
+		return data.ProjectName{}, time.Time{}, "", httphelper.NewHandlerError(
+			errgo.Notef(err, "can not use timestamp from form"), http.StatusBadRequest)
+	}
+
+	l.Debug("Project: ", project)
+	l.Debug("TimeStamp: ", timestamp)
+
+	return project, timestamp, value, nil
+}
+
+// addEntry records the entry and redirects back to the page of the project so
+// reloading the page does not post the form again.
+func addEntry(w http.ResponseWriter, r *http.Request, project data.ProjectName, entry data.Entry) *httphelper.HandlerError {
+	err := recordEntry(project, entry)
+	if err != nil {
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not record entry"))
+	}
+
+	http.Redirect(w, r, "/show/entries/"+project.String(), http.StatusSeeOther)
+
+	return nil
+}
+
+// formEntry writes the forms for adding notes and todos to the project.
+func formEntry(writer *document, project string) error {
+	tmpl, err := getAssetTemplate("templates/html_formEntry.html")
+	if err != nil {
+		return errgo.Notef(err, "can not get formEntry template")
+	}
+
+	err = writer.executeTemplate(tmpl, project)
+	if err != nil {
+		return errgo.Notef(err, "can not execute template formEntry")
+	}
+
+	return nil
+}
+
+func pageArchive(w http.ResponseWriter, r *http.Request, p httprouter.Params) *httphelper.HandlerError {
+	projects, err := dataStore.ListProjects(true)
+	if err != nil {
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not get list of projects"))
+	}
+
+	err = executePage(w, "templates/html_pageArchive.html", serverPaths{}, true, archivedProjects(projects))
+	if err != nil {
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not write pageArchive"))
+	}
+
+	return nil
+}
+
+func pageArchiveShow(w http.ResponseWriter, r *http.Request, p httprouter.Params) *httphelper.HandlerError {
+	l := httphelper.NewHandlerLogEntry(r)
+
+	name, err := data.ParseProjectName(p.ByName("project"))
+	if err != nil {
+		return httphelper.NewHandlerError(errgo.Notef(err, "can not parse project name"), http.StatusBadRequest)
+	}
+
+	l.Debug("Project: ", name)
+
+	projects, err := helper.ProjectsFromArgs(dataStore, []string{name.Archived().String()}, true)
+	if err != nil {
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not get projects"))
+	}
+
+	doc := newDocument()
+	err = writeEntries(doc, serverPaths{}, "Archive", name.Archived().String(), true, &projects, formatting.Project)
+	if err != nil {
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not write archive page"))
+	}
+
+	err = doc.render(render, w)
+	if err != nil {
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not render entries"))
+	}
+
+	return nil
+}
+
+func pageSearch(w http.ResponseWriter, r *http.Request, p httprouter.Params) *httphelper.HandlerError {
+	l := httphelper.NewHandlerLogEntry(r)
+
+	values := r.URL.Query()
+	query := search.Query{
+		Value:      values.Get("q"),
+		Regex:      values.Get("regex") == "true",
+		IgnoreCase: values.Get("ignorecase") == "true",
+	}
+
+	l.Debug("Query: ", query)
+
+	var args []string
+	if project := values.Get("project"); project != "" {
+		args = append(args, project)
+	}
 
-	projects, err := helper.ProjectNamesFromArgs(dataStore, []string{project}, false)
+	projects, err := helper.ProjectNamesFromArgs(dataStore, args, false)
 	if err != nil {
 		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not get list of projects"))
 	}
 
-	err = dataStore.PopulateProjects(&projects)
+	results, err := index.Search(dataDir, dataStore, query, projects)
 	if err != nil {
-		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not populate projects with entries"))
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not search projects"))
 	}
 
 	buffer := new(bytes.Buffer)
-	formatting.Projects(buffer, "Entries", 0, &projects)
+	formatting.SearchResults(buffer, query.Value, 0, results)
 
-	err = asciiDoctor(buffer, w)
+	err = render(buffer, w)
 	if err != nil {
-		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not format entries with asciidoctor"))
+		return httphelper.NewHandlerErrorDef(errgo.Notef(err, "can not render search results"))
 	}
 
 	return nil
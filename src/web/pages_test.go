@@ -0,0 +1,317 @@
+package web
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AlexanderThaller/lablog/src/asciidoc"
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/helper"
+	testhelper "github.com/AlexanderThaller/lablog/src/testing"
+
+	"github.com/AlexanderThaller/httphelper"
+	"github.com/julienschmidt/httprouter"
+)
+
+// tmpServer points the handlers to a new empty datadir without autocommit and
+// returns the datadir.
+func tmpServer(t *testing.T) string {
+	datadir, err := ioutil.TempDir("", "web_test_server")
+	if err != nil {
+		t.Fatal("can not open tmpdir: ", err)
+	}
+
+	dataDir = datadir
+	autoCommit = false
+	batcher = nil
+	render = asciidoc.Render
+
+	dataStore, err = helper.DefaultStore(datadir)
+	if err != nil {
+		t.Fatal("can not get data store: ", err)
+	}
+
+	return datadir
+}
+
+func postForm(target string, values url.Values) *http.Request {
+	r := httptest.NewRequest("POST", target, strings.NewReader(values.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return r
+}
+
+func projectParams(project string) httprouter.Params {
+	return httprouter.Params{{Key: "project", Value: project}}
+}
+
+func Test_PageAddNote(t *testing.T) {
+	tmpServer(t)
+
+	w := httptest.NewRecorder()
+	r := postForm("/projects/Test/notes", url.Values{
+		"value":     {"note"},
+		"timestamp": {"2010-11-10 23:00:00"},
+	})
+
+	herr := pageAddNote(w, r, projectParams("Test"))
+	if herr != nil {
+		t.Fatal("can not add note: ", herr.Error)
+	}
+
+	testhelper.CompareGotExpected(t, nil, w.Code, http.StatusSeeOther)
+	testhelper.CompareGotExpected(t, nil, w.Header().Get("Location"), "/show/entries/Test")
+
+	project, err := dataStore.GetProject(data.ProjectName{"Test"})
+	if err != nil {
+		t.Fatal("can not get project: ", err)
+	}
+
+	notes := project.Notes()
+	if len(notes) != 1 {
+		t.Fatal("expected one note but got: ", notes)
+	}
+	testhelper.CompareGotExpected(t, nil, notes[0].Value, "note")
+}
+
+func Test_PageAddTodoDone(t *testing.T) {
+	datadir := tmpServer(t)
+
+	existing := testhelper.GetTestTodo(0, "todo")
+	err := helper.RecordEntry(datadir, data.ProjectName{"Test"}, existing, false)
+	if err != nil {
+		t.Fatal("can not record todo: ", err)
+	}
+
+	r := postForm("/projects/Test/todos", url.Values{"value": {"todo"}, "done": {"true"}})
+	herr := pageAddTodo(httptest.NewRecorder(), r, projectParams("Test"))
+	if herr != nil {
+		t.Fatal("can not add todo: ", herr.Error)
+	}
+
+	project, err := dataStore.GetProject(data.ProjectName{"Test"})
+	if err != nil {
+		t.Fatal("can not get project: ", err)
+	}
+
+	todos := project.Todos()
+	if len(todos) != 1 {
+		t.Fatal("expected one todo but got: ", todos)
+	}
+	testhelper.CompareGotExpected(t, nil, todos[0].ID, existing.ID)
+	testhelper.CompareGotExpected(t, nil, todos[0].Active, false)
+}
+
+func Test_PageAddNoteInvalid(t *testing.T) {
+	tests := map[string]struct {
+		project string
+		values  url.Values
+	}{
+		"empty value":     {"Test", url.Values{"value": {" "}}},
+		"bad timestamp":   {"Test", url.Values{"value": {"note"}, "timestamp": {"not a time"}}},
+		"dots":            {"....", url.Values{"value": {"note"}}},
+		"git folder":      {".git.x", url.Values{"value": {"note"}}},
+		"archive":         {".archive.x", url.Values{"value": {"note"}}},
+		"path separators": {"a/../../x", url.Values{"value": {"note"}}},
+	}
+
+	for name, test := range tests {
+		datadir := tmpServer(t)
+
+		r := postForm("/projects/"+url.PathEscape(test.project)+"/notes", test.values)
+		herr := pageAddNote(httptest.NewRecorder(), r, projectParams(test.project))
+		if herr == nil {
+			t.Error(name, ": expected an error")
+			continue
+		}
+		testhelper.CompareGotExpected(t, nil, herr.Code, http.StatusBadRequest)
+
+		// Nothing is written, neither in nor next to the datadir.
+		files, err := ioutil.ReadDir(datadir)
+		testhelper.CompareGotExpected(t, err, len(files), 0)
+
+		_, err = os.Stat(datadir + ".csv")
+		if !os.IsNotExist(err) {
+			t.Error(name, ": expected no file next to the datadir")
+		}
+
+		_, err = os.Stat(filepath.Join(filepath.Dir(datadir), "x.csv"))
+		if !os.IsNotExist(err) {
+			t.Error(name, ": expected no file outside of the datadir")
+		}
+	}
+}
+
+func Test_SameOrigin(t *testing.T) {
+	tmpServer(t)
+
+	handler := sameOrigin(pageAddNote)
+
+	tests := map[string]int{
+		"":                           http.StatusSeeOther,
+		"http://example.com":         http.StatusSeeOther,
+		"http://attacker.example":    http.StatusForbidden,
+		"http://example.com.evil.io": http.StatusForbidden,
+	}
+
+	for origin, expected := range tests {
+		w := httptest.NewRecorder()
+		r := postForm("http://example.com/projects/Test/notes", url.Values{"value": {"note"}})
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+
+		code := http.StatusSeeOther
+		if herr := handler(w, r, projectParams("Test")); herr != nil {
+			code = herr.Code
+		}
+
+		testhelper.CompareGotExpected(t, nil, code, expected)
+	}
+}
+
+func Test_PageShowPassthrough(t *testing.T) {
+	datadir := tmpServer(t)
+
+	note := testhelper.GetTestNote(0, "++++\n<script>alert(1)</script>\n++++")
+	err := helper.RecordEntry(datadir, data.ProjectName{"Test"}, note, false)
+	if err != nil {
+		t.Fatal("can not record note: ", err)
+	}
+
+	w := httptest.NewRecorder()
+	params := httprouter.Params{{Key: "type", Value: "entries"}, {Key: "project", Value: "Test"}}
+
+	herr := pageShow(w, httptest.NewRequest("GET", "/show/entries/Test", nil), params)
+	if herr != nil {
+		t.Fatal("can not show project: ", herr.Error)
+	}
+
+	body := w.Body.String()
+	if strings.Contains(body, "<script>") {
+		t.Fatal("expected the passthrough block of the note to be escaped but got: ", body)
+	}
+
+	// The html of the templates is still part of the page.
+	for _, expected := range []string{`<form action="/projects/Test/notes" method="post">`, `href="/show/todos/Test"`} {
+		if !strings.Contains(body, expected) {
+			t.Error("expected the page to contain ", expected, " but got: ", body)
+		}
+	}
+}
+
+// showPage returns the body of the show page with the given type for the
+// project and the archive query.
+func showPage(t *testing.T, etype, project string, archive bool) (string, *httphelper.HandlerError) {
+	target := "/show/" + etype + "/" + project
+	if archive {
+		target += "?archive=true"
+	}
+
+	w := httptest.NewRecorder()
+	params := httprouter.Params{{Key: "type", Value: etype}, {Key: "project", Value: project}}
+
+	herr := pageShow(w, httptest.NewRequest("GET", target, nil), params)
+
+	return w.Body.String(), herr
+}
+
+func Test_PageShowTypes(t *testing.T) {
+	datadir := tmpServer(t)
+
+	note := testhelper.GetTestNote(0, "shown note")
+	todo := testhelper.GetTestTodo(1, "shown todo")
+	for _, entry := range []data.Entry{note, todo} {
+		err := helper.RecordEntry(datadir, data.ProjectName{"Test"}, entry, false)
+		if err != nil {
+			t.Fatal("can not record entry: ", err)
+		}
+	}
+
+	tests := map[string]struct {
+		contains []string
+		missing  []string
+	}{
+		"entries":  {[]string{"<title>Entries</title>", "shown note", "shown todo"}, nil},
+		"notes":    {[]string{"<title>Notes</title>", "shown note"}, []string{"shown todo"}},
+		"todos":    {[]string{"<title>Todos</title>", "shown todo"}, []string{"shown note"}},
+		"dates":    {[]string{"<title>Dates</title>", "2010-11-10", "2011-11-10"}, []string{"shown note"}},
+		"projects": {[]string{"<title>Projects</title>", `href="/show/entries/Test"`}, []string{"shown note"}},
+	}
+
+	for etype, test := range tests {
+		for _, project := range []string{"", "Test"} {
+			body, herr := showPage(t, etype, project, false)
+			if herr != nil {
+				t.Fatal("can not show ", etype, ": ", herr.Error)
+			}
+
+			for _, value := range test.contains {
+				if !strings.Contains(body, value) {
+					t.Error(etype, " ", project, ": expected the page to contain ", value)
+				}
+			}
+
+			for _, value := range test.missing {
+				if strings.Contains(body, value) {
+					t.Error(etype, " ", project, ": expected the page to not contain ", value)
+				}
+			}
+
+			// Only the page of a project has the forms to add entries.
+			form := strings.Contains(body, "<form")
+			testhelper.CompareGotExpected(t, nil, form, project != "")
+		}
+	}
+}
+
+func Test_PageShowUnknownType(t *testing.T) {
+	tmpServer(t)
+
+	_, herr := showPage(t, "other", "", false)
+	if herr == nil {
+		t.Fatal("expected an error for an unknown show type")
+	}
+	testhelper.CompareGotExpected(t, nil, herr.Code, http.StatusNotFound)
+}
+
+func Test_PageShowArchive(t *testing.T) {
+	datadir := tmpServer(t)
+
+	for _, name := range []data.ProjectName{{"Active"}, {data.ArchiveName, "Old"}} {
+		err := helper.RecordEntry(datadir, name, testhelper.GetTestNote(0, "note of "+name.String()), false)
+		if err != nil {
+			t.Fatal("can not record entry: ", err)
+		}
+	}
+
+	archived := "note of " + data.ArchiveName + ".Old"
+
+	body, herr := showPage(t, "entries", "", false)
+	if herr != nil {
+		t.Fatal("can not show entries: ", herr.Error)
+	}
+	if strings.Contains(body, archived) || !strings.Contains(body, "note of Active") {
+		t.Error("expected only the active project without archive but got: ", body)
+	}
+	if !strings.Contains(body, `href="/show/entries/?archive=true">with archive`) {
+		t.Error("expected a link to the page with archive but got: ", body)
+	}
+
+	body, herr = showPage(t, "entries", "", true)
+	if herr != nil {
+		t.Fatal("can not show entries with archive: ", herr.Error)
+	}
+	if !strings.Contains(body, archived) || !strings.Contains(body, "note of Active") {
+		t.Error("expected the archived and active project with archive but got: ", body)
+	}
+	if !strings.Contains(body, `href="/show/entries/">without archive`) {
+		t.Error("expected a link to the page without archive but got: ", body)
+	}
+}
@@ -0,0 +1,117 @@
+package web
+
+import (
+	"strings"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+)
+
+// paths builds the links between the pages. The webserver and the static
+// export lay out their pages differently. Methods return an empty string if
+// the page does not exist.
+type paths interface {
+	Root() string
+	Archive() string
+	Search() string
+	Show(etype, project string, archive bool) string
+	ArchiveProject(name data.ProjectName) string
+	Date(date string) string
+}
+
+// serverPaths are the paths of the routes of the webserver.
+type serverPaths struct{}
+
+func (serverPaths) Root() string {
+	return "/"
+}
+
+func (serverPaths) Archive() string {
+	return "/archive/"
+}
+
+func (serverPaths) Search() string {
+	return "/search"
+}
+
+func (serverPaths) Show(etype, project string, archive bool) string {
+	path := "/show/" + etype + "/" + project
+	if archive {
+		path += "?archive=true"
+	}
+
+	return path
+}
+
+func (serverPaths) ArchiveProject(name data.ProjectName) string {
+	return "/archive/" + name.Unarchived().String()
+}
+
+func (serverPaths) Date(date string) string {
+	return ""
+}
+
+// exportPaths are the relative paths between the files of the static export.
+// Pages for all projects are in the root of the export and pages for single
+// projects or dates are in a folder named after their type. The prefix leads
+// from the current page back to the root.
+type exportPaths struct {
+	prefix  string
+	archive bool
+}
+
+// newExportPaths returns the paths for the page in the given file.
+func newExportPaths(file string, archive bool) exportPaths {
+	return exportPaths{
+		prefix:  strings.Repeat("../", strings.Count(file, "/")),
+		archive: archive,
+	}
+}
+
+func (paths exportPaths) Root() string {
+	return paths.prefix + "index.html"
+}
+
+func (paths exportPaths) Archive() string {
+	if !paths.archive {
+		return ""
+	}
+
+	return paths.prefix + "archive.html"
+}
+
+func (exportPaths) Search() string {
+	return ""
+}
+
+// Show ignores archive as the export either contains the archive on all
+// pages or on none.
+func (paths exportPaths) Show(etype, project string, archive bool) string {
+	return paths.prefix + exportFile(etype, project)
+}
+
+func (paths exportPaths) ArchiveProject(name data.ProjectName) string {
+	return paths.Show("entries", name.Archived().String(), true)
+}
+
+func (paths exportPaths) Date(date string) string {
+	return paths.prefix + exportFile("date", date)
+}
+
+// exportFile returns the path of the page relative to the root of the export.
+// Archived projects are in an archive folder instead of files starting with a
+// dot, which would be hidden. The page for all archived projects is the index
+// of that folder so it does not clash with a project named archive.
+func exportFile(etype, name string) string {
+	if name == "" {
+		return etype + ".html"
+	}
+
+	switch {
+	case name == data.ArchiveName:
+		return etype + "/archive/index.html"
+	case strings.HasPrefix(name, data.ArchiveName+data.ProjectNameSepperator):
+		return etype + "/archive/" + strings.TrimPrefix(name, data.ArchiveName+data.ProjectNameSepperator) + ".html"
+	}
+
+	return etype + "/" + name + ".html"
+}
@@ -0,0 +1,183 @@
+package web
+
+import (
+	"io"
+
+	"github.com/AlexanderThaller/lablog/src/data"
+	"github.com/AlexanderThaller/lablog/src/formatting"
+	"github.com/AlexanderThaller/lablog/src/helper"
+	"github.com/juju/errgo"
+)
+
+// showTypes are the views of the show page that format the entries of each
+// project. They match the subcommands of the show command.
+var showTypes = map[string]struct {
+	Title  string
+	Format func(io.Writer, int, *data.Project)
+}{
+	"entries": {"Entries", formatting.Project},
+	"notes":   {"Notes", formatting.ProjectNotes},
+	"todos":   {"Todos", formatting.ProjectTodos},
+}
+
+// showTypeNames are the names of all views of the show page in the order
+// they are linked in the navigation.
+var showTypeNames = []string{"entries", "notes", "todos", "dates", "projects"}
+
+// errUnknownShowType is returned by writeShow for views that do not exist.
+var errUnknownShowType = errgo.New("the show type is not known")
+
+// writeShow writes the asciidoc document for the view of the show page with
+// the given type.
+func writeShow(writer *document, paths paths, etype, project string, archive bool, projects *data.Projects) error {
+	switch etype {
+	case "entries", "notes", "todos":
+		show := showTypes[etype]
+		return writeEntries(writer, paths, show.Title, project, archive, projects, show.Format)
+	case "dates":
+		err := writeHeader(writer, paths, "Dates", project, archive, projects)
+		if err != nil {
+			return err
+		}
+
+		for _, date := range helper.Dates(*projects) {
+			if path := paths.Date(date); path != "" {
+				io.WriteString(writer, "* link:"+path+"["+date+"]\n")
+			} else {
+				io.WriteString(writer, "* "+date+"\n")
+			}
+		}
+	case "projects":
+		err := writeHeader(writer, paths, "Projects", project, archive, projects)
+		if err != nil {
+			return err
+		}
+
+		for _, project := range projects.List() {
+			io.WriteString(writer, "* link:"+paths.Show("entries", project.Name.String(), archive)+
+				"["+project.Name.String()+"]\n")
+		}
+	default:
+		return errUnknownShowType
+	}
+
+	return nil
+}
+
+// writeEntries writes the asciidoc document with the entries of the projects
+// in the given format.
+func writeEntries(writer *document, paths paths, title, project string, archive bool,
+	projects *data.Projects, format func(io.Writer, int, *data.Project)) error {
+
+	err := writeHeader(writer, paths, title, project, archive, projects)
+	if err != nil {
+		return err
+	}
+
+	for _, project := range projects.List() {
+		format(writer, 1, &project)
+	}
+
+	return nil
+}
+
+func writeHeader(writer *document, paths paths, title, project string, archive bool, projects *data.Projects) error {
+	formatting.HeaderSettings(writer)
+	formatting.HeaderProjects(writer, title, 1, projects)
+
+	err := navigation(writer, paths, project, archive)
+	if err != nil {
+		return errgo.Notef(err, "can not write navigation")
+	}
+
+	return nil
+}
+
+type navigationLink struct {
+	Name string
+	Path string
+}
+
+// navigationData are the values for the navigation template.
+type navigationData struct {
+	Root    string
+	Archive string
+	Links   []navigationLink
+	// Toggle switches between showing and hiding the archive. It is empty
+	// if the paths do not depend on the archive.
+	Toggle navigationLink
+}
+
+func newNavigation(paths paths, project string, archive bool) navigationData {
+	values := navigationData{
+		Root:    paths.Root(),
+		Archive: paths.Archive(),
+	}
+
+	for _, etype := range showTypeNames {
+		values.Links = append(values.Links, navigationLink{Name: etype, Path: paths.Show(etype, project, archive)})
+	}
+
+	with, without := paths.Show("entries", project, true), paths.Show("entries", project, false)
+	if with != without {
+		values.Toggle = navigationLink{Name: "with archive", Path: with}
+		if archive {
+			values.Toggle = navigationLink{Name: "without archive", Path: without}
+		}
+	}
+
+	return values
+}
+
+// navigation writes the links between the views of the show page.
+func navigation(writer *document, paths paths, project string, archive bool) error {
+	tmpl, err := getAssetTemplate(navigationAsset)
+	if err != nil {
+		return errgo.Notef(err, "can not get navigation template")
+	}
+
+	err = writer.executeTemplate(tmpl, newNavigation(paths, project, archive))
+	if err != nil {
+		return errgo.Notef(err, "can not execute template navigation")
+	}
+
+	return nil
+}
+
+// pageData are the values for the templates of the root and the archive
+// page.
+type pageData struct {
+	Navigation navigationData
+	Paths      paths
+	Projects   []data.Project
+}
+
+func executePage(writer io.Writer, asset string, paths paths, archive bool, projects []data.Project) error {
+	tmpl, err := getAssetTemplate(asset)
+	if err != nil {
+		return errgo.Notef(err, "can not get template "+asset)
+	}
+
+	err = tmpl.Execute(writer, pageData{
+		Navigation: newNavigation(paths, "", archive),
+		Paths:      paths,
+		Projects:   projects,
+	})
+	if err != nil {
+		return errgo.Notef(err, "can not execute template "+asset)
+	}
+
+	return nil
+}
+
+// archivedProjects returns the projects that are in the archive.
+func archivedProjects(projects data.Projects) []data.Project {
+	var archived []data.Project
+	for _, project := range projects.List() {
+		if project.Name.IsArchived() {
+			archived = append(archived, project)
+		}
+	}
+
+	return archived
+}